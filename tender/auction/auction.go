@@ -0,0 +1,97 @@
+package auction
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"tender/models"
+)
+
+// Clock — источник текущего времени. Обработчики получают его снаружи,
+// чтобы ход раундов можно было проверять без ожидания.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// System возвращает системные часы.
+var System Clock = systemClock{}
+
+var (
+	ErrFinished = errors.New("Аукцион завершён.")
+	ErrExpired  = errors.New("Все раунды аукциона истекли, ожидается завершение.")
+	ErrStep     = errors.New("Новая цена должна быть ниже текущей лучшей как минимум на шаг аукциона.")
+)
+
+// New создаёт активный аукцион с первым раундом, который начинается в now.
+func New(request models.StartAuctionRequest, bestAmount float64, now time.Time) models.Auction {
+	return models.Auction{
+		Status:                 models.AUCTION_ACTIVE,
+		Currency:               request.Currency,
+		MinStep:                request.MinStep,
+		BestAmount:             bestAmount,
+		Round:                  1,
+		Rounds:                 request.Rounds,
+		RoundDurationSeconds:   request.RoundDurationSeconds,
+		ExtensionWindowSeconds: request.ExtensionWindowSeconds,
+		RoundEndsAt:            now.Add(time.Duration(request.RoundDurationSeconds) * time.Second),
+		StartedAt:              now,
+	}
+}
+
+// Advance переводит аукцион в раунд, идущий в момент now. Следующий раунд
+// начинается сразу по окончании предыдущего, с учётом его продлений.
+func Advance(a *models.Auction, now time.Time) {
+	duration := time.Duration(a.RoundDurationSeconds) * time.Second
+	for a.Status == models.AUCTION_ACTIVE && a.Round < a.Rounds && !now.Before(a.RoundEndsAt) {
+		a.Round++
+		a.RoundEndsAt = a.RoundEndsAt.Add(duration)
+	}
+}
+
+// Expired сообщает, что последний раунд истёк и ставки больше не принимаются.
+func Expired(a models.Auction, now time.Time) bool {
+	return a.Round >= a.Rounds && !now.Before(a.RoundEndsAt)
+}
+
+// Place принимает новую цену amount в момент now. Цена должна быть ниже
+// текущей лучшей хотя бы на MinStep. Ставка в последние ExtensionWindowSeconds
+// раунда продлевает его так, чтобы до конца оставалось не меньше окна продления.
+func Place(a *models.Auction, amount float64, now time.Time) error {
+	if a.Status != models.AUCTION_ACTIVE {
+		return ErrFinished
+	}
+	Advance(a, now)
+	if Expired(*a, now) {
+		return ErrExpired
+	}
+
+	// Сравнение в копейках, чтобы шаг 0.1 не срывался на погрешности float64
+	if math.Round(amount*100) > math.Round((a.BestAmount-a.MinStep)*100) {
+		return ErrStep
+	}
+	a.BestAmount = amount
+
+	window := time.Duration(a.ExtensionWindowSeconds) * time.Second
+	if extended := now.Add(window); extended.After(a.RoundEndsAt) {
+		a.RoundEndsAt = extended
+	}
+	return nil
+}
+
+// Finish завершает аукцион в момент now.
+func Finish(a *models.Auction, now time.Time) error {
+	if a.Status != models.AUCTION_ACTIVE {
+		return ErrFinished
+	}
+	Advance(a, now)
+	a.Status = models.AUCTION_FINISHED
+	a.FinishedAt = &now
+	return nil
+}
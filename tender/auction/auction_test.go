@@ -0,0 +1,150 @@
+package auction
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tender/models"
+)
+
+// fakeClock — часы, которые двигаются только вручную.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+var start = time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+func newAuction(clock Clock, bestAmount, minStep float64, rounds, roundSeconds, windowSeconds int) models.Auction {
+	return New(models.StartAuctionRequest{
+		Currency:               "RUB",
+		MinStep:                minStep,
+		Rounds:                 rounds,
+		RoundDurationSeconds:   roundSeconds,
+		ExtensionWindowSeconds: windowSeconds,
+	}, bestAmount, clock.Now())
+}
+
+func TestAdvance(t *testing.T) {
+	tests := []struct {
+		name      string
+		elapsed   time.Duration
+		wantRound int
+		wantEnds  time.Duration
+	}{
+		{"внутри первого раунда", 30 * time.Second, 1, 60 * time.Second},
+		{"ровно на границе раунда", 60 * time.Second, 2, 120 * time.Second},
+		{"через два раунда", 150 * time.Second, 3, 180 * time.Second},
+		{"после последнего раунда", 10 * time.Minute, 3, 180 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: start}
+			a := newAuction(clock, 1000, 10, 3, 60, 0)
+
+			clock.Add(tt.elapsed)
+			Advance(&a, clock.Now())
+
+			if a.Round != tt.wantRound {
+				t.Errorf("Round = %d, want %d", a.Round, tt.wantRound)
+			}
+			if want := start.Add(tt.wantEnds); !a.RoundEndsAt.Equal(want) {
+				t.Errorf("RoundEndsAt = %v, want %v", a.RoundEndsAt, want)
+			}
+		})
+	}
+}
+
+func TestAdvanceKeepsExtension(t *testing.T) {
+	clock := &fakeClock{now: start}
+	a := newAuction(clock, 1000, 10, 2, 60, 20)
+
+	// Ставка за 5 секунд до конца продлевает первый раунд до 75-й секунды
+	clock.Add(55 * time.Second)
+	if err := Place(&a, 990, clock.Now()); err != nil {
+		t.Fatalf("Place: %v", err)
+	}
+
+	// Второй раунд отсчитывается от продлённого конца первого
+	clock.Add(25 * time.Second)
+	Advance(&a, clock.Now())
+	if a.Round != 2 {
+		t.Fatalf("Round = %d, want 2", a.Round)
+	}
+	if want := start.Add(135 * time.Second); !a.RoundEndsAt.Equal(want) {
+		t.Errorf("RoundEndsAt = %v, want %v", a.RoundEndsAt, want)
+	}
+}
+
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		name     string
+		best     float64
+		minStep  float64
+		elapsed  time.Duration
+		amount   float64
+		wantErr  error
+		wantBest float64
+		wantEnds time.Duration
+	}{
+		{"ровно на шаг ниже", 1000, 10, 10 * time.Second, 990, nil, 990, 60 * time.Second},
+		{"ниже чем на шаг", 1000, 10, 10 * time.Second, 900, nil, 900, 60 * time.Second},
+		{"меньше шага", 1000, 10, 10 * time.Second, 995, ErrStep, 1000, 60 * time.Second},
+		{"выше лучшей цены", 1000, 10, 10 * time.Second, 1010, ErrStep, 1000, 60 * time.Second},
+		{"шаг 0.1 без погрешности float64", 0.3, 0.1, 10 * time.Second, 0.2, nil, 0.2, 60 * time.Second},
+		{"копейки округляются", 100.10, 0.05, 10 * time.Second, 100.05, nil, 100.05, 60 * time.Second},
+		{"в окне продления", 1000, 10, 50 * time.Second, 990, nil, 990, 70 * time.Second},
+		{"на границе окна продления", 1000, 10, 40 * time.Second, 990, nil, 990, 60 * time.Second},
+		{"во втором раунде", 1000, 10, 90 * time.Second, 990, nil, 990, 120 * time.Second},
+		{"после последнего раунда", 1000, 10, 120 * time.Second, 990, ErrExpired, 1000, 120 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: start}
+			a := newAuction(clock, tt.best, tt.minStep, 2, 60, 20)
+
+			clock.Add(tt.elapsed)
+			err := Place(&a, tt.amount, clock.Now())
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Place error = %v, want %v", err, tt.wantErr)
+			}
+			if a.BestAmount != tt.wantBest {
+				t.Errorf("BestAmount = %v, want %v", a.BestAmount, tt.wantBest)
+			}
+			if want := start.Add(tt.wantEnds); !a.RoundEndsAt.Equal(want) {
+				t.Errorf("RoundEndsAt = %v, want %v", a.RoundEndsAt, want)
+			}
+		})
+	}
+}
+
+func TestPlaceAfterFinish(t *testing.T) {
+	clock := &fakeClock{now: start}
+	a := newAuction(clock, 1000, 10, 1, 60, 0)
+
+	clock.Add(10 * time.Second)
+	if err := Finish(&a, clock.Now()); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if a.Status != models.AUCTION_FINISHED || a.FinishedAt == nil || !a.FinishedAt.Equal(clock.Now()) {
+		t.Fatalf("after Finish: status %s, finishedAt %v", a.Status, a.FinishedAt)
+	}
+
+	if err := Place(&a, 900, clock.Now()); !errors.Is(err, ErrFinished) {
+		t.Errorf("Place error = %v, want %v", err, ErrFinished)
+	}
+	if err := Finish(&a, clock.Now()); !errors.Is(err, ErrFinished) {
+		t.Errorf("second Finish error = %v, want %v", err, ErrFinished)
+	}
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActionCreate           = "CREATE"
+	ActionEdit             = "EDIT"
+	ActionRollback         = "ROLLBACK"
+	ActionStatusChange     = "STATUS_CHANGE"
+	ActionDecision         = "DECISION"
+	ActionCriteria         = "CRITERIA_SET"
+	ActionScore            = "SCORE"
+	ActionAuctionStart     = "AUCTION_START"
+	ActionAuctionBid       = "AUCTION_BID"
+	ActionShortlistAdd     = "SHORTLIST_ADD"
+	ActionShortlistRemove  = "SHORTLIST_REMOVE"
+	ActionInvitationAdd    = "INVITATION_ADD"
+	ActionInvitationRemove = "INVITATION_REMOVE"
+	ActionMemberAdd        = "MEMBER_ADD"
+	ActionMemberRole       = "MEMBER_ROLE_CHANGE"
+	ActionMemberRemove     = "MEMBER_REMOVE"
+	ActionAPIKeyCreate     = "API_KEY_CREATE"
+	ActionAPIKeyRevoke     = "API_KEY_REVOKE"
+	ActionAttachmentAdd    = "ATTACHMENT_ADD"
+	ActionAttachmentRemove = "ATTACHMENT_REMOVE"
+	ActionQuestionAsk      = "QUESTION_ASK"
+	ActionQuestionAnswer   = "QUESTION_ANSWER"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет идентификатор запроса в контексте.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFrom возвращает идентификатор запроса или пустую строку.
+func RequestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Record добавляет событие в журнал аудита. Автор события и идентификатор
+// запроса берутся из контекста; без них событие записывается от имени системы.
+// Ошибка записи только логируется, чтобы не ломать основное действие.
+func Record(ctx context.Context, action string, entityType models.AuditEntityType, entityID, organizationID string, before, after interface{}) {
+	event := models.AuditEvent{
+		ID:             uuid.New(),
+		ActorType:      models.ACTOR_SYSTEM,
+		Action:         action,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		OrganizationID: organizationID,
+		Before:         snapshot(before),
+		After:          snapshot(after),
+		RequestID:      RequestIDFrom(ctx),
+		CreatedAt:      time.Now(),
+	}
+
+	if employee := auth.EmployeeFrom(ctx); employee != nil {
+		event.ActorType = models.ACTOR_EMPLOYEE
+		event.ActorID = employee.ID.String()
+		event.ActorName = employee.Username
+	} else if principal := auth.OrganizationFrom(ctx); principal != nil {
+		event.ActorType = models.ACTOR_API_KEY
+		event.ActorID = principal.KeyID
+		event.ActorName = principal.OrganizationID
+	}
+
+	if err := db.DB.Create(&event).Error; err != nil {
+		log.Printf("Ошибка записи события аудита %s %s %s: %v", action, entityType, entityID, err)
+	}
+}
+
+func snapshot(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Ошибка сериализации снимка аудита: %v", err)
+		return nil
+	}
+	return raw
+}
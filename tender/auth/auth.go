@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"tender/models"
+)
+
+var (
+	ErrNoSecret     = errors.New("AUTH_SECRET is not set")
+	ErrWeakSecret   = errors.New("AUTH_SECRET must be at least 32 characters and not a placeholder")
+	ErrWeakAdmin    = errors.New("ADMIN_TOKEN must be at least 32 characters and not a placeholder")
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+const defaultTokenTTL = 24 * time.Hour
+
+const minSecretLength = 32
+
+// placeholders — значения из примеров конфигурации, с которыми сервис не запускается.
+var placeholders = map[string]bool{"changeme": true, "secret": true}
+
+// CheckConfig проверяет ключи перед запуском: AUTH_SECRET обязателен,
+// ADMIN_TOKEN может быть не задан (тогда административные эндпоинты закрыты),
+// но заданные значения не должны быть короткими или взятыми из примера.
+func CheckConfig() error {
+	key := os.Getenv("AUTH_SECRET")
+	if key == "" {
+		return ErrNoSecret
+	}
+	if len(key) < minSecretLength || placeholders[strings.ToLower(key)] {
+		return ErrWeakSecret
+	}
+
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" &&
+		(len(adminToken) < minSecretLength || placeholders[strings.ToLower(adminToken)]) {
+		return ErrWeakAdmin
+	}
+	return nil
+}
+
+// Claims — полезная нагрузка JWT, выдаваемого сотруднику.
+type Claims struct {
+	Subject   string `json:"sub"`
+	Username  string `json:"username"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+func secret() ([]byte, error) {
+	key := os.Getenv("AUTH_SECRET")
+	if key == "" {
+		return nil, ErrNoSecret
+	}
+	return []byte(key), nil
+}
+
+func tokenTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("AUTH_TOKEN_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return defaultTokenTTL
+}
+
+func sign(key []byte, data string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// IssueToken выдаёт подписанный HS256 токен для сотрудника.
+func IssueToken(employee models.Employee) (string, Claims, error) {
+	key, err := secret()
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	now := time.Now()
+	claims := Claims{
+		Subject:   employee.ID.String(),
+		Username:  employee.Username,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(tokenTTL()).Unix(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + sign(key, unsigned), claims, nil
+}
+
+// ParseToken проверяет подпись и срок действия токена.
+func ParseToken(token string) (Claims, error) {
+	var claims Claims
+
+	key, err := secret()
+	if err != nil {
+		return claims, err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return claims, ErrInvalidToken
+	}
+
+	expected := sign(key, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return claims, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, ErrInvalidToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return claims, ErrExpiredToken
+	}
+
+	return claims, nil
+}
+
+// LegacyUsernameEnabled сообщает, разрешена ли идентификация через параметр ?username=.
+// Нужна только для совместимости с тестами конкурса.
+func LegacyUsernameEnabled() bool {
+	return os.Getenv("AUTH_ALLOW_USERNAME_PARAM") == "true"
+}
+
+type contextKey struct{}
+
+type organizationKey struct{}
+
+// WithEmployee сохраняет текущего сотрудника в контексте запроса.
+func WithEmployee(ctx context.Context, employee *models.Employee) context.Context {
+	return context.WithValue(ctx, contextKey{}, employee)
+}
+
+// EmployeeFrom возвращает текущего сотрудника или nil, если запрос анонимный.
+func EmployeeFrom(ctx context.Context) *models.Employee {
+	employee, _ := ctx.Value(contextKey{}).(*models.Employee)
+	return employee
+}
+
+// OrganizationPrincipal — организация, аутентифицированная API-ключом.
+type OrganizationPrincipal struct {
+	OrganizationID string
+	KeyID          string
+	Scopes         []models.APIKeyScope
+}
+
+func (p *OrganizationPrincipal) HasScope(scope models.APIKeyScope) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// WithOrganization сохраняет организацию, аутентифицированную API-ключом, в контексте запроса.
+func WithOrganization(ctx context.Context, principal *OrganizationPrincipal) context.Context {
+	return context.WithValue(ctx, organizationKey{}, principal)
+}
+
+// OrganizationFrom возвращает организацию из контекста или nil.
+func OrganizationFrom(ctx context.Context) *OrganizationPrincipal {
+	principal, _ := ctx.Value(organizationKey{}).(*OrganizationPrincipal)
+	return principal
+}
+
+const apiKeyPrefix = "tsk_"
+
+// GenerateAPIKey создаёт новый API-ключ. Возвращает сам ключ, его короткий
+// префикс для отображения и хеш для хранения.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return key, key[:len(apiKeyPrefix)+8], HashAPIKey(key), nil
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func JoinScopes(scopes []models.APIKeyScope) string {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	return strings.Join(values, " ")
+}
+
+func SplitScopes(scopes string) []models.APIKeyScope {
+	fields := strings.Fields(scopes)
+	values := make([]models.APIKeyScope, len(fields))
+	for i, field := range fields {
+		values[i] = models.APIKeyScope(field)
+	}
+	return values
+}
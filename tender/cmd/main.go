@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 
+	"tender/auth"
 	"tender/db"
 	"tender/handlers"
+	"tender/models"
+	"tender/scheduler"
+	"tender/storage"
 
 	"github.com/gorilla/mux"
 )
@@ -16,28 +21,92 @@ func main() {
 
 	serverAddress := os.Getenv("SERVER_ADDRESS")
 
+	if err := auth.CheckConfig(); err != nil {
+		log.Fatalf("Invalid auth configuration: %v", err)
+	}
+
 	db.Connect()
 	db.Migrate()
+	storage.Open()
+
+	scheduler.Start(context.Background(), scheduler.Tick())
 
 	router := mux.NewRouter()
+	router.Use(handlers.RequestID)
+	router.Use(handlers.Authenticate)
 
 	router.HandleFunc("/api/ping", handlers.PingHandler).Methods(http.MethodGet)
 	//Tender routes
 	router.HandleFunc("/api/tenders", handlers.GetTendersHandler).Methods(http.MethodGet)
 	router.HandleFunc("/api/tenders/new", handlers.CreateTenderHandler).Methods(http.MethodPost)
 	router.HandleFunc("/api/tenders/my", handlers.GetUserTendersHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/search", handlers.SearchTendersHandler).Methods(http.MethodGet)
 	router.HandleFunc("/api/tenders/{tenderId}/status", handlers.GetTenderStatusHandler).Methods(http.MethodGet)
 	router.HandleFunc("/api/tenders/{tenderId}/status", handlers.UpdateTenderStatusHandler).Methods(http.MethodPut)
 	router.HandleFunc("/api/tenders/{tenderId}/edit", handlers.EditTenderHandler).Methods(http.MethodPatch)
 	router.HandleFunc("/api/tenders/{tenderId}/rollback/{version}", handlers.RollbackTenderHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/tenders/{tenderId}/versions", handlers.GetTenderVersionsHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/bids/matrix", handlers.GetBidMatrixHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/bids/compare", handlers.GetBidComparisonHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/criteria", handlers.GetEvaluationCriteriaHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/criteria", handlers.SetEvaluationCriteriaHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/tenders/{tenderId}/evaluation", handlers.GetTenderEvaluationHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/auction", handlers.GetAuctionHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/auction/start", handlers.StartAuctionHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/auction/bids", handlers.PlaceAuctionBidHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/auction/finish", handlers.FinishAuctionHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/stage/advance", handlers.AdvanceTenderStageHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/shortlist", handlers.GetShortlistHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/shortlist", handlers.AddToShortlistHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/shortlist/{authorId}", handlers.RemoveFromShortlistHandler).Methods(http.MethodDelete)
+	router.HandleFunc("/api/tenders/{tenderId}/invitations", handlers.GetInvitationsHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/invitations", handlers.CreateInvitationHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/invitations/{inviteeId}", handlers.DeleteInvitationHandler).Methods(http.MethodDelete)
+	router.HandleFunc("/api/tenders/{tenderId}/questions", handlers.GetQuestionsHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/questions", handlers.AskQuestionHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/questions/{questionId}/answer", handlers.AnswerQuestionHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/tenders/{tenderId}/attachments", handlers.GetTenderAttachmentsHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/attachments", handlers.UploadTenderAttachmentHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/attachments/{attachmentId}", handlers.DownloadTenderAttachmentHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/attachments/{attachmentId}", handlers.DeleteTenderAttachmentHandler).Methods(http.MethodDelete)
 	// Bid routes
 	router.HandleFunc("/api/bids/new", handlers.CreateBidHandler).Methods(http.MethodPost)
 	router.HandleFunc("/api/bids/my", handlers.GetUserBidsHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/bids/search", handlers.SearchBidsHandler).Methods(http.MethodGet)
 	router.HandleFunc("/api/bids/{tenderId}/list", handlers.GetBidsForTenderHandler).Methods(http.MethodGet)
 	router.HandleFunc("/api/bids/{bidId}/status", handlers.GetBidStatusHandler).Methods(http.MethodGet)
 	router.HandleFunc("/api/bids/{bidId}/status", handlers.UpdateBidStatusHandler).Methods(http.MethodPut)
 	router.HandleFunc("/api/bids/{bidId}/edit", handlers.EditBidHandler).Methods(http.MethodPatch)
 	router.HandleFunc("/api/bids/{bidId}/rollback/{version}", handlers.RollbackBidHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/bids/{bidId}/submit_decision", handlers.SubmitBidDecisionHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/bids/{bidId}/versions", handlers.GetBidVersionsHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/bids/{bidId}/scores", handlers.SubmitBidScoresHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/bids/{bidId}/attachments", handlers.GetBidAttachmentsHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/bids/{bidId}/attachments", handlers.UploadBidAttachmentHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/bids/{bidId}/attachments/{attachmentId}", handlers.DownloadBidAttachmentHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/bids/{bidId}/attachments/{attachmentId}", handlers.DeleteBidAttachmentHandler).Methods(http.MethodDelete)
+	// Audit routes
+	router.HandleFunc("/api/audit", handlers.GetAuditHandler).Methods(http.MethodGet)
+	// Organization integration routes
+	router.HandleFunc("/api/organizations/{organizationId}/api-keys", handlers.GetAPIKeysHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/organizations/{organizationId}/api-keys", handlers.CreateAPIKeyHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/organizations/{organizationId}/api-keys/{keyId}", handlers.RevokeAPIKeyHandler).Methods(http.MethodDelete)
+	// Admin routes
+	router.HandleFunc("/api/auth/token", handlers.AdminOnly(handlers.IssueTokenHandler)).Methods(http.MethodPost)
+	router.HandleFunc("/api/employees", handlers.AdminOnly(handlers.GetEmployeesHandler)).Methods(http.MethodGet)
+	router.HandleFunc("/api/employees", handlers.AdminOnly(handlers.CreateEmployeeHandler)).Methods(http.MethodPost)
+	router.HandleFunc("/api/employees/{employeeId}", handlers.AdminOnly(handlers.GetEmployeeHandler)).Methods(http.MethodGet)
+	router.HandleFunc("/api/employees/{employeeId}", handlers.AdminOnly(handlers.EditEmployeeHandler)).Methods(http.MethodPatch)
+	router.HandleFunc("/api/employees/{employeeId}", handlers.AdminOnly(handlers.DeleteEmployeeHandler)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/organizations", handlers.AdminOnly(handlers.GetOrganizationsHandler)).Methods(http.MethodGet)
+	router.HandleFunc("/api/organizations", handlers.AdminOnly(handlers.CreateOrganizationHandler)).Methods(http.MethodPost)
+	router.HandleFunc("/api/organizations/{organizationId}", handlers.AdminOnly(handlers.GetOrganizationHandler)).Methods(http.MethodGet)
+	router.HandleFunc("/api/organizations/{organizationId}", handlers.AdminOnly(handlers.EditOrganizationHandler)).Methods(http.MethodPatch)
+	router.HandleFunc("/api/organizations/{organizationId}", handlers.AdminOnly(handlers.DeleteOrganizationHandler)).Methods(http.MethodDelete)
+	router.HandleFunc("/api/organizations/{organizationId}/responsibles", handlers.AdminOrOrganizationPermission(models.PERMISSION_READ, handlers.GetResponsiblesHandler)).Methods(http.MethodGet)
+	router.HandleFunc("/api/organizations/{organizationId}/responsibles", handlers.AdminOrOrganizationPermission(models.PERMISSION_MANAGE_MEMBERS, handlers.AddResponsibleHandler)).Methods(http.MethodPost)
+	router.HandleFunc("/api/organizations/{organizationId}/responsibles/{userId}", handlers.AdminOrOrganizationPermission(models.PERMISSION_MANAGE_MEMBERS, handlers.UpdateResponsibleRoleHandler)).Methods(http.MethodPut)
+	router.HandleFunc("/api/organizations/{organizationId}/responsibles/{userId}", handlers.AdminOrOrganizationPermission(models.PERMISSION_MANAGE_MEMBERS, handlers.RemoveResponsibleHandler)).Methods(http.MethodDelete)
 
 	log.Printf("Server is running on port %s\n", serverAddress)
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", serverAddress), router))
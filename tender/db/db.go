@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -13,6 +14,19 @@ import (
 
 var DB *gorm.DB
 
+// enums создаются при каждом запуске; уже существующий тип пропускается,
+// чтобы сервис поднимался на заполненной базе и доходил до миграций.
+var enums = []struct {
+	name, values string
+}{
+	{"organization_type", "'IE', 'LLC', 'JSC'"},
+	{"tender_status", "'CREATED', 'PUBLISHED', 'CLOSED'"},
+	{"tender_service_type", "'CONSTRUCTION', 'DELIVERY', 'MANUFACTURE'"},
+	{"bid_status", "'CREATED', 'PUBLISHED', 'CANCELED'"},
+	{"bid_author_type", "'ORGANIZATION', 'USER'"},
+	{"organization_role", "'owner', 'editor', 'approver', 'viewer'"},
+}
+
 func Connect() {
 	var err error
 
@@ -23,7 +37,8 @@ func Connect() {
 		os.Getenv("POSTGRES_DB"),
 		"5432")
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError переводит нарушения уникальности в gorm.ErrDuplicatedKey
+	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 
 	if err != nil {
 		log.Fatal("Failed to connect to Postgres", err)
@@ -34,31 +49,174 @@ func Connect() {
 		log.Fatalf("Failed to enable uuid-ossp extension: %v", err)
 	}
 
-	err = DB.Exec(`CREATE TYPE organization_type AS ENUM ('IE', 'LLC', 'JSC')`).Error
-	if err != nil {
-		log.Fatalf("failed to create enum type: %v", err)
+	for _, enum := range enums {
+		err = DB.Exec(fmt.Sprintf(`DO $$ BEGIN
+			CREATE TYPE %s AS ENUM (%s);
+		EXCEPTION WHEN duplicate_object THEN NULL;
+		END $$`, enum.name, enum.values)).Error
+		if err != nil {
+			log.Fatalf("failed to create enum type %s: %v", enum.name, err)
+		}
 	}
+}
 
-	err = DB.Exec(`CREATE TYPE tender_status AS ENUM ('CREATED', 'PUBLISHED', 'CLOSED')`).Error
+func Migrate() {
+	migrateReferences()
+
+	err := DB.AutoMigrate(&models.Employee{}, &models.Organization{}, &models.OrganizationResponsible{}, &models.Tender{}, &models.TenderVersion{}, &models.Bid{}, &models.BidVersion{}, &models.OrganizationAPIKey{}, &models.AuditEvent{}, &models.EvaluationCriterion{}, &models.BidScore{}, &models.Auction{}, &models.AuctionBid{}, &models.ShortlistEntry{}, &models.TenderInvitation{}, &models.TenderQuestion{}, &models.Attachment{}, &models.BidDecision{})
 	if err != nil {
-		log.Fatalf("failed to create enum type: %v", err)
+		log.Fatalf("failed to migrate: %v", err)
+	}
+
+	runOnce("close_superseded_tenders", migrateSupersededTenders)
+	runOnce("cancel_superseded_bids", migrateSupersededBids)
+	migrateSearch()
+}
+
+// dataMigration отмечает однократную миграцию данных, уже выполненную на этой базе.
+type dataMigration struct {
+	Name      string    `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time `gorm:"type:timestamptz;not null"`
+}
+
+// runOnce выполняет миграцию данных и запоминает её в data_migrations в той же
+// транзакции. Повторные запуски сервиса её пропускают: такие миграции исправляют
+// данные прежних версий и не должны трогать строки, записанные после них.
+func runOnce(name string, migrate func(tx *gorm.DB) error) {
+	if err := DB.AutoMigrate(&dataMigration{}); err != nil {
+		log.Fatalf("failed to migrate data_migrations: %v", err)
 	}
-	err = DB.Exec(`CREATE TYPE tender_service_type AS ENUM ('CONSTRUCTION', 'DELIVERY', 'MANUFACTURE')`).Error
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		// Блокировка не даёт двум репликам выполнить миграцию одновременно
+		if err := tx.Exec(`LOCK TABLE data_migrations IN EXCLUSIVE MODE`).Error; err != nil {
+			return err
+		}
+		var applied int64
+		if err := tx.Model(&dataMigration{}).Where("name = ?", name).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			return nil
+		}
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		return tx.Create(&dataMigration{Name: name, AppliedAt: time.Now()}).Error
+	})
 	if err != nil {
-		log.Fatalf("failed to create enum type: %v", err)
+		log.Fatalf("failed to run data migration %s: %v", name, err)
+	}
+}
+
+// migrateSupersededTenders закрывает строки тендеров, которые прежние версии
+// сервиса оставляли при редактировании и откате: новая версия получала новый id,
+// а старая строка оставалась открытой рядом со снимком своей же версии.
+// Предложения по таким строкам замораживаются вместе с ними.
+func migrateSupersededTenders(tx *gorm.DB) error {
+	return tx.Exec(`UPDATE tenders SET status = 'CLOSED' WHERE status <> 'CLOSED'
+		AND EXISTS (SELECT 1 FROM tender_versions v WHERE v.tender_id = tenders.id AND v.version = tenders.version)`).Error
+}
+
+// migrateSupersededBids отменяет строки предложений, которые прежние версии
+// сервиса оставляли при редактировании: правка создавала новую строку с новым id,
+// а старая сохранялась со снимком своей же версии.
+func migrateSupersededBids(tx *gorm.DB) error {
+	return tx.Exec(`UPDATE bids SET status = 'CANCELED' WHERE status <> 'CANCELED'
+		AND EXISTS (SELECT 1 FROM bid_versions v WHERE v.bid_id = bids.id AND v.version = bids.version)`).Error
+}
+
+// searchVector строит tsvector по названию и описанию сразу в русской и английской конфигурациях.
+// Название весит больше описания.
+const searchVector = `setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('russian', coalesce(description, '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(description, '')), 'B')`
+
+func migrateSearch() {
+	for _, table := range []string{"tenders", "bids"} {
+		err := DB.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (%s) STORED`, table, searchVector)).Error
+		if err != nil {
+			log.Fatalf("failed to add search_vector to %s: %v", table, err)
+		}
+
+		err = DB.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_search_vector ON %s USING GIN (search_vector)`, table, table)).Error
+		if err != nil {
+			log.Fatalf("failed to create search index on %s: %v", table, err)
+		}
+	}
+}
+
+const uuidPattern = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+
+// columnType возвращает тип колонки или пустую строку, если таблицы или колонки ещё нет.
+func columnType(table, column string) string {
+	var dataType string
+	DB.Raw(`SELECT data_type FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`, table, column).Scan(&dataType)
+	return dataType
+}
+
+// convertToUUID переводит текстовую колонку в uuid. Если в ней есть значения,
+// которые не являются UUID, миграция останавливается: такие строки нужно
+// исправить вручную, иначе внешний ключ не создать.
+func convertToUUID(table, column string) {
+	dataType := columnType(table, column)
+	if dataType == "" || dataType == "uuid" {
+		return
 	}
 
-	err = DB.Exec(`CREATE TYPE bid_status AS ENUM ('CREATED', 'PUBLISHED', 'CANCELED')`).Error
+	var invalid int64
+	err := DB.Raw(fmt.Sprintf(`SELECT count(*) FROM %s WHERE %s !~ ?`, table, column), uuidPattern).Scan(&invalid).Error
 	if err != nil {
-		log.Fatalf("failed to create enum type: %v", err)
+		log.Fatalf("failed to check %s.%s: %v", table, column, err)
+	}
+	if invalid > 0 {
+		log.Fatalf("%s.%s: %d rows are not valid UUIDs, fix them before migrating", table, column, invalid)
 	}
 
-	err = DB.Exec(`CREATE TYPE bid_author_type AS ENUM ('ORGANIZATION', 'USER')`).Error
+	// Значение по умолчанию (например, uuid_generate_v4() у bids.author_id) мешает смене типа
+	err = DB.Exec(fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT, ALTER COLUMN %s TYPE uuid USING %s::uuid`, table, column, column, column)).Error
 	if err != nil {
-		log.Fatalf("failed to create enum type: %v", err)
+		log.Fatalf("failed to convert %s.%s to uuid: %v", table, column, err)
 	}
 }
 
-func Migrate() {
-	DB.AutoMigrate(&models.Employee{}, &models.Organization{}, &models.OrganizationResponsible{}, &models.Tender{}, &models.TenderVersion{}, &models.Bid{}, &models.BidVersion{})
+// foreignKeys перечисляет ссылки, которые раньше хранились строками. Для каждой
+// проверяется, что все значения указывают на существующие строки, иначе
+// AutoMigrate не сможет создать внешний ключ.
+var foreignKeys = []struct {
+	table, column, references string
+}{
+	{"tenders", "organization_id", "organizations"},
+	{"tender_versions", "tender_id", "tenders"},
+	{"bids", "tender_id", "tenders"},
+	{"bid_versions", "bid_id", "bids"},
+}
+
+// migrateReferences переводит строковые идентификаторы тендеров, предложений
+// и их версий в uuid перед тем, как AutoMigrate добавит внешние ключи и индексы.
+func migrateReferences() {
+	for _, fk := range foreignKeys {
+		convertToUUID(fk.table, fk.column)
+	}
+	// Не ссылки, но хранят те же идентификаторы
+	convertToUUID("tender_versions", "organization_id")
+	convertToUUID("bids", "author_id")
+	convertToUUID("bid_versions", "tender_id")
+	convertToUUID("bid_versions", "author_id")
+
+	for _, fk := range foreignKeys {
+		if columnType(fk.table, fk.column) == "" || columnType(fk.references, "id") == "" {
+			continue
+		}
+
+		var orphans int64
+		err := DB.Raw(fmt.Sprintf(`SELECT count(*) FROM %s t WHERE NOT EXISTS (SELECT 1 FROM %s r WHERE r.id = t.%s)`, fk.table, fk.references, fk.column)).Scan(&orphans).Error
+		if err != nil {
+			log.Fatalf("failed to check %s.%s: %v", fk.table, fk.column, err)
+		}
+		if orphans > 0 {
+			log.Fatalf("%s.%s: %d rows reference missing %s, fix them before migrating", fk.table, fk.column, orphans, fk.references)
+		}
+	}
 }
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"tender/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var setup sync.Once
+
+func requireDB(t *testing.T) {
+	t.Helper()
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST не задан, тест с базой пропущен")
+	}
+	setup.Do(func() {
+		Connect()
+		Migrate()
+	})
+}
+
+func TestRunOnce(t *testing.T) {
+	requireDB(t)
+
+	name := "test_" + uuid.NewString()
+	runs := 0
+	migrate := func(tx *gorm.DB) error {
+		runs++
+		return nil
+	}
+
+	runOnce(name, migrate)
+	runOnce(name, migrate)
+
+	if runs != 1 {
+		t.Fatalf("migration ran %d times, want 1", runs)
+	}
+	DB.Where("name = ?", name).Delete(&dataMigration{})
+}
+
+func TestMigrateSupersededTenders(t *testing.T) {
+	requireDB(t)
+
+	organization := models.Organization{ID: uuid.New(), Name: "test", Type: models.LLC}
+	if err := DB.Create(&organization).Error; err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+
+	// Старая правка оставила строку версии 1 рядом с её же снимком,
+	// новая правка на месте — строку версии 2 со снимком версии 1
+	create := func(version uint) models.Tender {
+		tender := models.Tender{
+			ID:             uuid.New(),
+			Name:           "Тендер",
+			Description:    "Описание",
+			Status:         models.TENDER_PUBLISHED,
+			ServiceType:    models.CONSTRUCTION,
+			OrganizationID: organization.ID,
+			Version:        version,
+			CreatedAt:      time.Now(),
+		}
+		if err := DB.Omit("Organization").Create(&tender).Error; err != nil {
+			t.Fatalf("create tender: %v", err)
+		}
+		snapshot := tender
+		snapshot.Version = 1
+		tenderVersion := snapshot.Snapshot()
+		if err := DB.Omit("Tender").Create(&tenderVersion).Error; err != nil {
+			t.Fatalf("create tender version: %v", err)
+		}
+		return tender
+	}
+	superseded := create(1)
+	current := create(2)
+
+	if err := DB.Transaction(migrateSupersededTenders); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	for _, tt := range []struct {
+		tender models.Tender
+		want   models.TenderStatus
+	}{
+		{superseded, models.TENDER_CLOSED},
+		{current, models.TENDER_PUBLISHED},
+	} {
+		var tender models.Tender
+		if err := DB.First(&tender, "id = ?", tt.tender.ID).Error; err != nil {
+			t.Fatalf("reload tender: %v", err)
+		}
+		if tender.Status != tt.want {
+			t.Errorf("tender version %d: status = %s, want %s", tt.tender.Version, tender.Status, tt.want)
+		}
+	}
+}
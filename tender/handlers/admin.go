@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"os"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func isAdmin(r *http.Request) bool {
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	token := r.Header.Get("X-Admin-Token")
+	return adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
+// AdminOnly пропускает запрос, только если заголовок X-Admin-Token совпадает
+// с переменной окружения ADMIN_TOKEN. Без ADMIN_TOKEN административные
+// эндпоинты недоступны.
+func AdminOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isAdmin(r) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("Доступ разрешён только администратору.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// AdminOrOrganizationPermission пропускает администратора или сотрудника,
+// у которого есть право permission в организации {organizationId} из пути.
+func AdminOrOrganizationPermission(permission models.OrganizationPermission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isAdmin(r) {
+			next(w, r)
+			return
+		}
+
+		organizationId, err := uuid.Parse(mux.Vars(r)["organizationId"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+
+		if !authorizeOrganization(w, r, organizationId, permission) {
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"tender/audit"
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm/clause"
+)
+
+func apiKeyResponse(key models.OrganizationAPIKey) models.APIKeyResponse {
+	return models.APIKeyResponse{
+		ID:             key.ID.String(),
+		OrganizationID: key.OrganizationID.String(),
+		Name:           key.Name,
+		Prefix:         key.Prefix,
+		Scopes:         auth.SplitScopes(key.Scopes),
+		CreatedAt:      key.CreatedAt,
+		LastUsedAt:     key.LastUsedAt,
+		RevokedAt:      key.RevokedAt,
+	}
+}
+
+func CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+
+	organizationId, err := uuid.Parse(mux.Vars(r)["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !authorizeOrganization(w, r, organizationId, models.PERMISSION_MANAGE_MEMBERS) {
+		return
+	}
+
+	var newAPIKeyRequest models.NewAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&newAPIKeyRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if newAPIKeyRequest.Name == "" || len(newAPIKeyRequest.Name) > 100 || len(newAPIKeyRequest.Scopes) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поля name и scopes обязательны, name не длиннее 100 символов."))
+		return
+	}
+
+	for _, scope := range newAPIKeyRequest.Scopes {
+		if !scope.IsValid() {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Допустимые scopes: tenders:write, bids:read, decisions:write."))
+			return
+		}
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка генерации API-ключа: %v", err)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка при создании API-ключа."))
+		return
+	}
+
+	apiKey := models.OrganizationAPIKey{
+		ID:             uuid.New(),
+		OrganizationID: organizationId,
+		Name:           newAPIKeyRequest.Name,
+		Prefix:         prefix,
+		KeyHash:        hash,
+		Scopes:         auth.JoinScopes(newAPIKeyRequest.Scopes),
+		CreatedAt:      time.Now(),
+	}
+
+	if err := db.DB.Omit("Organization").Create(&apiKey).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при сохранении API-ключа: %v", err)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка при создании API-ключа."))
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionAPIKeyCreate, models.ENTITY_ORGANIZATION, organizationId.String(), organizationId.String(), nil, apiKeyResponse(apiKey))
+
+	// Сам ключ возвращается только один раз, в базе хранится его хеш
+	response := apiKeyResponse(apiKey)
+	response.Key = key
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+
+	organizationId, err := uuid.Parse(mux.Vars(r)["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !authorizeOrganization(w, r, organizationId, models.PERMISSION_MANAGE_MEMBERS) {
+		return
+	}
+
+	var keys []models.OrganizationAPIKey
+	if err := db.DB.Where("organization_id = ?", organizationId).Order("created_at DESC, id ASC").Find(&keys).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении API-ключей.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	keyResponses := make([]models.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		keyResponses[i] = apiKeyResponse(key)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keyResponses)
+}
+
+func RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	organizationId, err := uuid.Parse(vars["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	keyId, err := uuid.Parse(vars["keyId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора API-ключа.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !authorizeOrganization(w, r, organizationId, models.PERMISSION_MANAGE_MEMBERS) {
+		return
+	}
+
+	var revoked []models.OrganizationAPIKey
+	result := db.DB.Model(&revoked).Clauses(clause.Returning{}).
+		Where("id = ? AND organization_id = ? AND revoked_at IS NULL", keyId, organizationId).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при отзыве API-ключа.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if result.RowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := models.NewErrorResponse("API-ключ не найден или уже отозван.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	after := apiKeyResponse(revoked[0])
+	before := after
+	before.RevokedAt = nil
+	audit.Record(r.Context(), audit.ActionAPIKeyRevoke, models.ENTITY_ORGANIZATION, organizationId.String(), organizationId.String(), before, after)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,532 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"tender/audit"
+	"tender/db"
+	"tender/models"
+	"tender/storage"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxAttachmentSize     = 20 << 20
+	maxAttachments        = 50
+	maxAttachmentFileName = 255
+	sniffLength           = 512
+)
+
+var errAttachmentLimit = errors.New("Можно прикрепить не больше 50 файлов.")
+
+// Тип файла определяется по содержимому, а не по заголовку клиента.
+// Документы docx и xlsx распознаются как application/zip.
+var allowedAttachmentTypes = map[string]bool{
+	"application/pdf":           true,
+	"application/zip":           true,
+	"image/png":                 true,
+	"image/jpeg":                true,
+	"text/plain; charset=utf-8": true,
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// receiveAttachment принимает файл из поля file multipart-запроса, кладёт его
+// в хранилище и сохраняет метаданные. Лимит вложений перепроверяется под
+// блокировкой тендера или предложения, чтобы одновременные загрузки его не превысили.
+// При ошибке пишет ответ и возвращает false.
+func receiveAttachment(w http.ResponseWriter, r *http.Request, attachment *models.Attachment) bool {
+	// Запас сверх лимита файла — на заголовки multipart
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentSize+1<<20)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Ожидается multipart/form-data с файлом в поле file.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+
+	var part *multipart.Part
+	for {
+		part, err = reader.NextPart()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := models.NewErrorResponse("Ожидается multipart/form-data с файлом в поле file.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return false
+		}
+		if part.FormName() == "file" {
+			break
+		}
+	}
+	defer part.Close()
+
+	fileName := filepath.Base(part.FileName())
+	if fileName == "" || fileName == "." || fileName == "/" || len(fileName) > maxAttachmentFileName {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Имя файла обязательно и не должно быть длиннее 255 символов.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Ошибка чтения файла.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+	head = head[:n]
+	if n == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Файл пуст.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+
+	contentType := http.DetectContentType(head)
+	if !allowedAttachmentTypes[contentType] {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		errorResponse := models.NewErrorResponse("Допустимые типы файлов: PDF, ZIP (в том числе DOCX и XLSX), PNG, JPEG и текст.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+
+	attachment.ID = uuid.New()
+	key := attachment.ID.String()
+	counter := &countingReader{r: io.MultiReader(bytes.NewReader(head), io.LimitReader(part, maxAttachmentSize-int64(n)+1))}
+
+	if err := storage.Blobs.Put(r.Context(), key, counter); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			errorResponse := models.NewErrorResponse("Размер файла не должен превышать 20 МБ.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при сохранении вложения: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при сохранении файла.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+
+	if counter.n > maxAttachmentSize {
+		if err := storage.Blobs.Delete(r.Context(), key); err != nil {
+			log.Printf("Ошибка при удалении вложения %s: %v", key, err)
+		}
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		errorResponse := models.NewErrorResponse("Размер файла не должен превышать 20 МБ.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+
+	attachment.FileName = fileName
+	attachment.ContentType = contentType
+	attachment.Size = counter.n
+	attachment.CreatedAt = time.Now()
+
+	var parent interface{} = &models.Tender{}
+	column, parentID := "tender_id", attachment.TenderID
+	if attachment.BidID != nil {
+		parent = &models.Bid{}
+		column, parentID = "bid_id", attachment.BidID
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(parent, "id = ?", parentID).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.Attachment{}).Where(column+" = ?", parentID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxAttachments {
+			return errAttachmentLimit
+		}
+		return tx.Omit("Tender", "Bid").Create(attachment).Error
+	})
+	if err != nil {
+		if err := storage.Blobs.Delete(r.Context(), key); err != nil {
+			log.Printf("Ошибка при удалении вложения %s: %v", key, err)
+		}
+		if errors.Is(err, errAttachmentLimit) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+			return false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при сохранении вложения: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при сохранении вложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+	return true
+}
+
+// attachmentLimitReached проверяет, не превышено ли число вложений у тендера или предложения,
+// до приёма файла. Окончательная проверка — в receiveAttachment.
+func attachmentLimitReached(w http.ResponseWriter, column string, id uuid.UUID) bool {
+	var count int64
+	if err := db.DB.Model(&models.Attachment{}).Where(column+" = ?", id).Count(&count).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении вложений.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return true
+	}
+	if count >= maxAttachments {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse(errAttachmentLimit.Error()))
+		return true
+	}
+	return false
+}
+
+func listAttachments(w http.ResponseWriter, column string, id uuid.UUID) {
+	var attachments []models.Attachment
+	if err := db.DB.Where(column+" = ?", id).Order("created_at ASC").Find(&attachments).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении вложений.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// findAttachment загружает вложение из пути, принадлежащее тендеру или предложению id.
+func findAttachment(w http.ResponseWriter, r *http.Request, column string, id uuid.UUID) (models.Attachment, bool) {
+	var attachment models.Attachment
+
+	attachmentId, err := uuid.Parse(mux.Vars(r)["attachmentId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора вложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return attachment, false
+	}
+
+	if err := db.DB.Where("id = ? AND "+column+" = ?", attachmentId, id).First(&attachment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Вложение не найдено.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return attachment, false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении вложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return attachment, false
+	}
+	return attachment, true
+}
+
+func serveAttachment(w http.ResponseWriter, r *http.Request, attachment models.Attachment) {
+	blob, err := storage.Blobs.Get(r.Context(), attachment.ID.String())
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Файл вложения не найден в хранилище.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при чтении файла.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, blob); err != nil {
+		log.Printf("Ошибка при отдаче вложения %s: %v", attachment.ID, err)
+	}
+}
+
+// removeAttachment удаляет вложение и возвращает удалённую строку для журнала аудита.
+// При ошибке пишет ответ и возвращает false.
+func removeAttachment(w http.ResponseWriter, r *http.Request, attachment models.Attachment) (models.Attachment, bool) {
+	var removed []models.Attachment
+	result := db.DB.Clauses(clause.Returning{}).Where("id = ?", attachment.ID).Delete(&removed)
+	if result.Error != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при удалении вложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return attachment, false
+	}
+	if result.RowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := models.NewErrorResponse("Вложение не найдено.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return attachment, false
+	}
+	// Метаданные уже удалены, поэтому ошибка хранилища оставляет лишь бесхозный файл
+	if err := storage.Blobs.Delete(r.Context(), attachment.ID.String()); err != nil {
+		log.Printf("Ошибка при удалении файла вложения %s: %v", attachment.ID, err)
+	}
+	return removed[0], true
+}
+
+func UploadTenderAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, employee, ok := authorizeTender(w, r, models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
+	}
+
+	if attachmentLimitReached(w, "tender_id", tender.ID) {
+		return
+	}
+
+	attachment := models.Attachment{TenderID: &tender.ID, UploadedByID: employee.ID}
+	if !receiveAttachment(w, r, &attachment) {
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionAttachmentAdd, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), nil, attachment)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+// GetTenderAttachmentsHandler и DownloadTenderAttachmentHandler доступны всем,
+// кому виден сам тендер.
+func GetTenderAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, _, _, ok := loadVisibleTender(w, r)
+	if !ok {
+		return
+	}
+
+	listAttachments(w, "tender_id", tender.ID)
+}
+
+func DownloadTenderAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, _, _, ok := loadVisibleTender(w, r)
+	if !ok {
+		return
+	}
+
+	attachment, ok := findAttachment(w, r, "tender_id", tender.ID)
+	if !ok {
+		return
+	}
+
+	serveAttachment(w, r, attachment)
+}
+
+func DeleteTenderAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, _, ok := authorizeTender(w, r, models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
+	}
+
+	attachment, ok := findAttachment(w, r, "tender_id", tender.ID)
+	if !ok {
+		return
+	}
+
+	attachment, ok = removeAttachment(w, r, attachment)
+	if !ok {
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionAttachmentRemove, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), attachment, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadBidAttachments загружает предложение из пути и проверяет доступ к его
+// вложениям. Менять вложения может только автор, пока предложение не заморожено;
+// смотреть — ещё и организация тендера, при закрытых торгах после срока подачи.
+func loadBidAttachments(w http.ResponseWriter, r *http.Request, modify bool) (models.Bid, *models.Employee, bool) {
+	var bid models.Bid
+
+	bidId, err := uuid.Parse(mux.Vars(r)["bidId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return bid, nil, false
+	}
+
+	username := currentUsername(r)
+	if username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return bid, nil, false
+	}
+
+	var employee models.Employee
+	if err := db.DB.Where("username = ?", username).First(&employee).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusUnauthorized)
+			errorResponse := models.NewErrorResponse("Пользователь не существует или некорректен.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return bid, nil, false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return bid, nil, false
+	}
+
+	if err := db.DB.First(&bid, "id = ?", bidId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Предложение не найдено.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return bid, nil, false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return bid, nil, false
+	}
+
+	author, err := isBidAuthor(employee, bid)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при проверке ответственных.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return bid, nil, false
+	}
+
+	if modify {
+		if !author {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("Недостаточно прав для выполнения действия.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return bid, nil, false
+		}
+		if bidsFrozen(w, bid.TenderID) {
+			return bid, nil, false
+		}
+		return bid, &employee, true
+	}
+
+	if !author {
+		var tender models.Tender
+		if err := db.DB.First(&tender, "id = ?", bid.TenderID).Error; err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return bid, nil, false
+		}
+		if !authorizeEmployee(w, &employee, tender.OrganizationID.String(), models.PERMISSION_READ) {
+			return bid, nil, false
+		}
+		if bidsSealed(tender) {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("Предложения закрытых торгов скрыты до окончания срока подачи.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return bid, nil, false
+		}
+	}
+	return bid, &employee, true
+}
+
+func UploadBidAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+
+	bid, employee, ok := loadBidAttachments(w, r, true)
+	if !ok {
+		return
+	}
+
+	if attachmentLimitReached(w, "bid_id", bid.ID) {
+		return
+	}
+
+	attachment := models.Attachment{BidID: &bid.ID, UploadedByID: employee.ID}
+	if !receiveAttachment(w, r, &attachment) {
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionAttachmentAdd, models.ENTITY_BID, bid.ID.String(), tenderOrganizationID(bid.TenderID), nil, attachment)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+func GetBidAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+
+	bid, _, ok := loadBidAttachments(w, r, false)
+	if !ok {
+		return
+	}
+
+	listAttachments(w, "bid_id", bid.ID)
+}
+
+func DownloadBidAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+
+	bid, _, ok := loadBidAttachments(w, r, false)
+	if !ok {
+		return
+	}
+
+	attachment, ok := findAttachment(w, r, "bid_id", bid.ID)
+	if !ok {
+		return
+	}
+
+	serveAttachment(w, r, attachment)
+}
+
+func DeleteBidAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+
+	bid, _, ok := loadBidAttachments(w, r, true)
+	if !ok {
+		return
+	}
+
+	attachment, ok := findAttachment(w, r, "bid_id", bid.ID)
+	if !ok {
+		return
+	}
+
+	attachment, ok = removeAttachment(w, r, attachment)
+	if !ok {
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionAttachmentRemove, models.ENTITY_BID, bid.ID.String(), tenderOrganizationID(bid.TenderID), attachment, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"tender/auction"
+	"tender/audit"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxAuctionRounds        = 20
+	minAuctionRoundDuration = 30
+	maxAuctionRoundDuration = 24 * 60 * 60
+)
+
+// auctionClock — часы, по которым идут раунды аукциона; подменяется в тестах.
+var auctionClock = auction.System
+
+var (
+	errInvalidAuction         = errors.New("Обязательны currency (код ISO 4217), minStep больше нуля, rounds от 1 до 20, roundDurationSeconds от 30 до 86400 и extensionWindowSeconds от 0 до roundDurationSeconds.")
+	errAuctionCurrency        = errors.New("Валюта предложения не совпадает с валютой аукциона.")
+	errAuctionTenderNotPublic = errors.New("Аукцион проводится только по опубликованным тендерам.")
+)
+
+func validateAuctionRequest(request *models.StartAuctionRequest) error {
+	request.Currency = strings.ToUpper(request.Currency)
+	if !currencyCode.MatchString(request.Currency) ||
+		request.MinStep <= 0 || request.MinStep >= maxBudgetOrPrice ||
+		request.Rounds < 1 || request.Rounds > maxAuctionRounds ||
+		request.RoundDurationSeconds < minAuctionRoundDuration || request.RoundDurationSeconds > maxAuctionRoundDuration ||
+		request.ExtensionWindowSeconds < 0 || request.ExtensionWindowSeconds > request.RoundDurationSeconds {
+		return errInvalidAuction
+	}
+	return nil
+}
+
+// StartAuctionHandler запускает редукцион по опубликованному тендеру на поставку.
+// Стартовая цена — лучшая среди опубликованных предложений в валюте аукциона.
+func StartAuctionHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, _, ok := authorizeTender(w, r, models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
+	}
+
+	if tender.ServiceType != models.DELIVERY {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Аукцион проводится только по тендерам на поставку (DELIVERY).")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if tender.Status != models.TENDER_PUBLISHED {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(errAuctionTenderNotPublic.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Ставки аукциона открыты, что противоречит закрытым торгам
+	if tender.Sealed {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Аукцион недоступен при закрытых торгах.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var request models.StartAuctionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if err := validateAuctionRequest(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		return
+	}
+
+	now := auctionClock.Now()
+	end := now.Add(time.Duration(request.Rounds*request.RoundDurationSeconds) * time.Second)
+	if tender.SubmissionDeadline != nil && end.After(*tender.SubmissionDeadline) {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Раунды аукциона должны закончиться до срока подачи предложений.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var best *float64
+	if err := db.DB.Model(&models.Bid{}).Select("MIN(amount)").
+		Where("tender_id = ? AND status = ? AND stage = ? AND currency = ?", tender.ID, models.BID_PUBLISHED, models.STAGE_COMMERCIAL, request.Currency).
+		Scan(&best).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении заявок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if best == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Нет опубликованных предложений с ценой в валюте аукциона.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Второй аукцион по тендеру отсекает уникальный индекс по tender_id,
+	// в том числе при одновременном запуске
+	a := auction.New(request, *best, now)
+	a.ID = uuid.New()
+	a.TenderID = tender.ID
+	if err := db.DB.Omit("Tender").Create(&a).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			w.WriteHeader(http.StatusConflict)
+			errorResponse := models.NewErrorResponse("Аукцион по тендеру уже проводился.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при создании аукциона: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при создании аукциона.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionAuctionStart, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), nil, a)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.AuctionResponse{Auction: a})
+}
+
+// GetAuctionHandler возвращает состояние аукциона. Текущую лучшую цену и
+// раунд видит каждый, кому виден тендер, историю ставок — только организация тендера.
+func GetAuctionHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, _, owner, ok := loadVisibleTender(w, r)
+	if !ok {
+		return
+	}
+
+	var a models.Auction
+	if err := db.DB.First(&a, "tender_id = ?", tender.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Аукцион по тендеру не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении аукциона.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	auction.Advance(&a, auctionClock.Now())
+
+	response := models.AuctionResponse{Auction: a}
+
+	if owner {
+		if err := db.DB.Where("auction_id = ?", a.ID).Order("created_at ASC").Find(&response.History).Error; err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := models.NewErrorResponse("Ошибка при получении истории ставок.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// PlaceAuctionBidHandler принимает новую цену от автора опубликованного предложения.
+// Цена предложения меняется при завершении аукциона, до тех пор хранится история ставок.
+func PlaceAuctionBidHandler(w http.ResponseWriter, r *http.Request) {
+
+	tenderId, err := uuid.Parse(mux.Vars(r)["tenderId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	username := currentUsername(r)
+	if username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var request models.AuctionBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	bidId, err := uuid.Parse(request.BidID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if request.Amount <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse(errInvalidAmount.Error()))
+		return
+	}
+
+	var employee models.Employee
+	if err := db.DB.Where("username = ?", username).First(&employee).Error; err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не существует или некорректен.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var bid models.Bid
+	if err := db.DB.Preload("Tender").First(&bid, "id = ? AND tender_id = ?", bidId, tenderId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Предложение по тендеру не найдено.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Ставку делает автор предложения: сам сотрудник или ответственный организации-автора
+	if bid.AuthorType == models.AUTHOR_USER {
+		if bid.AuthorID != employee.ID {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("Недостаточно прав для выполнения действия.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+	} else if !authorizeEmployee(w, &employee, bid.AuthorID.String(), models.PERMISSION_READ) {
+		return
+	}
+
+	if bid.Tender.Status != models.TENDER_PUBLISHED {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Тендер закрыт, ставки больше не принимаются.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if bid.Status != models.BID_PUBLISHED || bid.Stage != models.STAGE_COMMERCIAL || bid.Amount == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("В аукционе участвуют только опубликованные предложения с ценой.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var a models.Auction
+	var step models.AuctionBid
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&a, "tender_id = ?", tenderId).Error; err != nil {
+			return err
+		}
+		if bid.Currency != a.Currency {
+			return errAuctionCurrency
+		}
+		if err := auction.Place(&a, request.Amount, auctionClock.Now()); err != nil {
+			return err
+		}
+		if err := tx.Omit("Tender").Save(&a).Error; err != nil {
+			return err
+		}
+		step = models.AuctionBid{
+			ID:        uuid.New(),
+			AuctionID: a.ID,
+			Round:     a.Round,
+			BidID:     bid.ID,
+			Amount:    request.Amount,
+			CreatedAt: auctionClock.Now(),
+		}
+		return tx.Omit("Auction", "Bid").Create(&step).Error
+	})
+	if err != nil {
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Аукцион по тендеру не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+		case errors.Is(err, errAuctionCurrency), errors.Is(err, auction.ErrFinished), errors.Is(err, auction.ErrExpired), errors.Is(err, auction.ErrStep):
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("Ошибка при сохранении ставки аукциона: %v", err)
+			errorResponse := models.NewErrorResponse("Ошибка при сохранении ставки.")
+			json.NewEncoder(w).Encode(errorResponse)
+		}
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionAuctionBid, models.ENTITY_BID, bid.ID.String(), bid.Tender.OrganizationID.String(), nil, step)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.AuctionResponse{Auction: a})
+}
+
+type repricedBid struct {
+	before models.Bid
+	after  models.Bid
+}
+
+// FinishAuctionHandler завершает аукцион и переносит последнюю ставку каждого
+// участника в цену его предложения новой версией. Цены по позициям при этом
+// сбрасываются: итоговая сумма задана аукционом, а не позициями.
+func FinishAuctionHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, employee, ok := authorizeTender(w, r, models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
+	}
+
+	var a models.Auction
+	var repriced []repricedBid
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Разделяемая блокировка не даёт закрыть тендер, пока переносятся цены
+		var current models.Tender
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).First(&current, "id = ?", tender.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != models.TENDER_PUBLISHED {
+			return errAuctionTenderNotPublic
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&a, "tender_id = ?", tender.ID).Error; err != nil {
+			return err
+		}
+		if err := auction.Finish(&a, auctionClock.Now()); err != nil {
+			return err
+		}
+		if err := tx.Omit("Tender").Save(&a).Error; err != nil {
+			return err
+		}
+
+		var last []models.AuctionBid
+		if err := tx.Raw("SELECT DISTINCT ON (bid_id) * FROM auction_bids WHERE auction_id = ? ORDER BY bid_id, created_at DESC", a.ID).Scan(&last).Error; err != nil {
+			return err
+		}
+
+		for _, step := range last {
+			var bid models.Bid
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bid, "id = ?", step.BidID).Error; err != nil {
+				return err
+			}
+
+			bidVersion := bid.Snapshot()
+			if err := tx.Omit("Bid").Create(&bidVersion).Error; err != nil {
+				return err
+			}
+
+			amount := step.Amount
+			after := bid
+			after.Amount = &amount
+			after.LineItems = nil
+			after.UpdatedByID = &employee.ID
+			after.Version = bid.Version + 1
+			if err := tx.Omit("Tender").Save(&after).Error; err != nil {
+				return err
+			}
+			repriced = append(repriced, repricedBid{before: bid, after: after})
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Аукцион по тендеру не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+		case errors.Is(err, errAuctionTenderNotPublic):
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		case errors.Is(err, auction.ErrFinished):
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("Ошибка при завершении аукциона: %v", err)
+			errorResponse := models.NewErrorResponse("Ошибка при завершении аукциона.")
+			json.NewEncoder(w).Encode(errorResponse)
+		}
+		return
+	}
+
+	for _, bid := range repriced {
+		audit.Record(r.Context(), audit.ActionEdit, models.ENTITY_BID, bid.after.ID.String(), tender.OrganizationID.String(), bid.before, bid.after)
+	}
+
+	response := models.AuctionResponse{Auction: a}
+	if err := db.DB.Where("auction_id = ?", a.ID).Order("created_at ASC").Find(&response.History).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении истории ставок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"tender/db"
+	"tender/models"
+)
+
+var auctionRequest = models.StartAuctionRequest{
+	Currency:               "RUB",
+	MinStep:                10,
+	Rounds:                 1,
+	RoundDurationSeconds:   60,
+	ExtensionWindowSeconds: 0,
+}
+
+// createAuctionTender создаёт опубликованный тендер на поставку с одним
+// предложением в рублях, по которому можно запустить аукцион.
+func createAuctionTender(t *testing.T) (models.Employee, models.Tender) {
+	t.Helper()
+
+	owner := createEmployee(t)
+	supplier := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, func(tender *models.Tender) {
+		tender.ServiceType = models.DELIVERY
+	})
+	createBid(t, tender, supplier, models.BID_PUBLISHED, func(bid *models.Bid) {
+		amount := 1000.0
+		bid.Amount = &amount
+		bid.Currency = "RUB"
+	})
+	return owner, tender
+}
+
+func TestStartAuctionConcurrent(t *testing.T) {
+	requireDB(t)
+
+	owner, tender := createAuctionTender(t)
+
+	const starts = 8
+	codes := make([]int, starts)
+	var wg sync.WaitGroup
+	for i := 0; i < starts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serve(t, http.MethodPost, "/api/tenders/"+tender.ID.String()+"/auction/start", auctionRequest, asEmployee(owner)).Code
+		}(i)
+	}
+	wg.Wait()
+
+	started := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			started++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("unexpected status %d", code)
+		}
+	}
+	if started != 1 {
+		t.Fatalf("started %d auctions, want 1", started)
+	}
+}
+
+func TestFinishAuctionClosedTender(t *testing.T) {
+	requireDB(t)
+
+	owner, tender := createAuctionTender(t)
+	w := serve(t, http.MethodPost, "/api/tenders/"+tender.ID.String()+"/auction/start", auctionRequest, asEmployee(owner))
+	if w.Code != http.StatusOK {
+		t.Fatalf("start: status = %d: %s", w.Code, w.Body.String())
+	}
+	if err := db.DB.Model(&models.Tender{}).Where("id = ?", tender.ID).Update("status", models.TENDER_CLOSED).Error; err != nil {
+		t.Fatalf("close tender: %v", err)
+	}
+
+	w = serve(t, http.MethodPost, "/api/tenders/"+tender.ID.String()+"/auction/finish", nil, asEmployee(owner))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"tender/audit"
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var sortByCreatedAtDesc = sortOrder{Key: "createdAt", Column: "created_at", Desc: true}
+
+// RequestID берёт идентификатор запроса из заголовка X-Request-ID или создаёт новый,
+// возвращает его в ответе и кладёт в контекст для журнала аудита.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" || len(requestID) > 100 {
+			requestID = uuid.New().String()
+		}
+
+		w.Header().Set("X-Request-ID", requestID)
+		next.ServeHTTP(w, r.WithContext(audit.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// tenderOrganizationID возвращает организацию тендера, к которому относится предложение.
+func tenderOrganizationID(tenderID uuid.UUID) string {
+	var tender models.Tender
+	if err := db.DB.Select("organization_id").First(&tender, "id = ?", tenderID).Error; err != nil {
+		log.Printf("Ошибка при получении организации тендера %s: %v", tenderID, err)
+		return ""
+	}
+	return tender.OrganizationID.String()
+}
+
+func GetAuditHandler(w http.ResponseWriter, r *http.Request) {
+
+	query := db.DB.Model(&models.AuditEvent{})
+
+	// Администратор видит весь журнал, ответственные — только события своих организаций
+	if !isAdmin(r) {
+		employee := auth.EmployeeFrom(r.Context())
+		if employee == nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+
+		organizationIDs, err := userOrganizationIDs(employee.ID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := models.NewErrorResponse("Ошибка при получении организации.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		if len(organizationIDs) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("Недостаточно прав для выполнения действия.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		query = query.Where("organization_id IN ?", organizationIDs)
+		// Снимки предложений закрытых торгов раскрываются только после срока подачи
+		query = query.Where(`NOT (entity_type = ? AND entity_id IN (
+			SELECT b.id::text FROM bids b JOIN tenders t ON t.id = b.tender_id WHERE t.`+sealedTendersCondition+`))`,
+			models.ENTITY_BID, time.Now())
+	}
+
+	params := r.URL.Query()
+
+	if entityType := params.Get("entity_type"); entityType != "" {
+		entityType = strings.ToUpper(entityType)
+		switch models.AuditEntityType(entityType) {
+		case models.ENTITY_TENDER, models.ENTITY_BID, models.ENTITY_ORGANIZATION:
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := models.NewErrorResponse("Параметр entity_type должен быть TENDER, BID или ORGANIZATION.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	if entityID := params.Get("entity_id"); entityID != "" {
+		query = query.Where("entity_id = ?", entityID)
+	}
+
+	if actor := params.Get("actor"); actor != "" {
+		query = query.Where("(actor_name = ? OR actor_id = ?)", actor, actor)
+	}
+
+	if from := params.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := models.NewErrorResponse("Неверный формат параметра from, ожидается RFC3339.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+
+	if to := params.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := models.NewErrorResponse("Неверный формат параметра to, ожидается RFC3339.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		query = query.Where("created_at < ?", t)
+	}
+
+	pg, err := parsePage(r, sortByCreatedAtDesc)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении журнала аудита.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var events []models.AuditEvent
+	if err := pg.apply(query).Find(&events).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при получении журнала аудита: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при получении журнала аудита.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var last pageCursor
+	if len(events) > 0 {
+		event := events[len(events)-1]
+		last = pg.cursor(event.CreatedAt.Format(time.RFC3339Nano), event.ID)
+	}
+
+	writePageHeaders(w, pg, total, len(events), last)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(events)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Authenticate определяет текущего сотрудника по заголовку Authorization: Bearer <token>
+// и кладёт его в контекст запроса. Интеграции организаций аутентифицируются
+// заголовком X-API-Key. Если включён режим совместимости
+// (AUTH_ALLOW_USERNAME_PARAM=true), сотрудник берётся из параметра username.
+// Запросы без идентификации пропускаются анонимно, решение принимает обработчик.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+
+		if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+			var key models.OrganizationAPIKey
+			if err := db.DB.Where("key_hash = ? AND revoked_at IS NULL", auth.HashAPIKey(apiKey)).First(&key).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnauthorized)
+					errorResponse := models.NewErrorResponse("API-ключ недействителен или отозван.")
+					json.NewEncoder(w).Encode(errorResponse)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				errorResponse := models.NewErrorResponse("Ошибка при проверке API-ключа.")
+				json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+
+			if err := db.DB.Model(&key).Update("last_used_at", time.Now()).Error; err != nil {
+				log.Printf("Ошибка при обновлении last_used_at API-ключа %s: %v", key.ID, err)
+			}
+
+			r = r.WithContext(auth.WithOrganization(r.Context(), &auth.OrganizationPrincipal{
+				OrganizationID: key.OrganizationID.String(),
+				KeyID:          key.ID.String(),
+				Scopes:         auth.SplitScopes(key.Scopes),
+			}))
+		} else if authorization != "" {
+			token, ok := strings.CutPrefix(authorization, "Bearer ")
+			if !ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				errorResponse := models.NewErrorResponse("Неверный формат заголовка Authorization.")
+				json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+
+			claims, err := auth.ParseToken(token)
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				errorResponse := models.NewErrorResponse("Токен недействителен или истёк.")
+				json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+
+			var employee models.Employee
+			if err := db.DB.First(&employee, "id = ?", claims.Subject).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnauthorized)
+					errorResponse := models.NewErrorResponse("Пользователь не существует или некорректен.")
+					json.NewEncoder(w).Encode(errorResponse)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+				json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+
+			r = r.WithContext(auth.WithEmployee(r.Context(), &employee))
+		} else if username := r.URL.Query().Get("username"); username != "" && auth.LegacyUsernameEnabled() {
+			var employee models.Employee
+			if err := db.DB.Where("username = ?", username).First(&employee).Error; err == nil {
+				r = r.WithContext(auth.WithEmployee(r.Context(), &employee))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// currentUsername возвращает username аутентифицированного сотрудника.
+// В режиме совместимости возвращает параметр username как есть, чтобы
+// обработчик сам ответил на несуществующего пользователя.
+func currentUsername(r *http.Request) string {
+	if employee := auth.EmployeeFrom(r.Context()); employee != nil {
+		return employee.Username
+	}
+	if auth.LegacyUsernameEnabled() {
+		return r.URL.Query().Get("username")
+	}
+	return ""
+}
+
+func IssueTokenHandler(w http.ResponseWriter, r *http.Request) {
+
+	var tokenRequest models.TokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&tokenRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if tokenRequest.Username == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поле username обязательно."))
+		return
+	}
+
+	var employee models.Employee
+	if err := db.DB.Where("username = ?", tokenRequest.Username).First(&employee).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Пользователь не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	token, claims, err := auth.IssueToken(employee)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при выдаче токена: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при выдаче токена.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	tokenResponse := models.TokenResponse{
+		Token:     token,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tokenResponse)
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var bidSortColumns = map[string]string{
+	"name":           "name",
+	"createdAt":      "created_at",
+	"amount":         "amount",
+	"deliveryDate":   "delivery_date",
+	"warrantyMonths": "warranty_months",
+}
+
+// bidSortEmpty — чем заменяется пустое поле при сортировке, чтобы предложение
+// без значения оказалось в конце списка при любом направлении: [0] — по
+// возрастанию, [1] — по убыванию. Тем же значением заполняется курсор.
+var bidSortEmpty = map[string][2]string{
+	"amount":         {"1e18", "-1"},
+	"deliveryDate":   {"'infinity'", "'-infinity'"},
+	"warrantyMonths": {"2147483647", "-1"},
+}
+
+// bidSortOrder подставляет в колонку сортировки значение для пустых полей.
+func bidSortOrder(sort sortOrder) sortOrder {
+	if empty, ok := bidSortEmpty[sort.Key]; ok {
+		sort.Column = fmt.Sprintf("COALESCE(%s, %s)", sort.Column, empty[bidSortDirection(sort)])
+	}
+	return sort
+}
+
+func bidSortDirection(sort sortOrder) int {
+	if sort.Desc {
+		return 1
+	}
+	return 0
+}
+
+const maxWarrantyMonths = 600
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var (
+	errInvalidAmount         = errors.New("Поле amount должно быть больше нуля.")
+	errInvalidCurrency       = errors.New("Поле currency должно быть трёхбуквенным кодом ISO 4217, например RUB.")
+	errMissingCurrency       = errors.New("Для поля amount обязательно поле currency.")
+	errInvalidDeliveryDate   = errors.New("Поле deliveryDate должно быть в будущем.")
+	errInvalidWarrantyMonths = errors.New("Поле warrantyMonths должно быть от 0 до 600.")
+	errInvalidPaymentTerms   = errors.New("Допустимые paymentTerms: PREPAYMENT, PARTIAL_PREPAYMENT, POSTPAYMENT.")
+	errUnknownLineItem       = errors.New("Позиция lineItemId не найдена в тендере.")
+	errDuplicateBidLineItem  = errors.New("Позиция lineItemId указана в предложении несколько раз.")
+	errInvalidBidLineItem    = errors.New("У позиции предложения quantity и unitPrice должны быть больше нуля.")
+	errAmountWithLineItems   = errors.New("Сумма предложения с позициями вычисляется по lineItems, поле amount передавать не нужно.")
+	errPriceInQualification  = errors.New("Квалификационная заявка не содержит цены: amount и lineItems подаются на коммерческом этапе.")
+	errBidChanged            = errors.New("Предложение изменилось, пока обрабатывался запрос. Повторите правку.")
+)
+
+// applyBidTerms проверяет коммерческие условия из запроса и переносит в bid
+// только переданные поля, поэтому при редактировании остальные условия сохраняются.
+func applyBidTerms(bid *models.Bid, request models.NewBidRequest) error {
+	if request.Amount != nil {
+		if *request.Amount <= 0 || *request.Amount >= maxBudgetOrPrice {
+			return errInvalidAmount
+		}
+		bid.Amount = request.Amount
+	}
+
+	if request.Currency != "" {
+		currency := strings.ToUpper(request.Currency)
+		if !currencyCode.MatchString(currency) {
+			return errInvalidCurrency
+		}
+		bid.Currency = currency
+	}
+
+	if request.DeliveryDate != nil {
+		if !request.DeliveryDate.After(time.Now()) {
+			return errInvalidDeliveryDate
+		}
+		bid.DeliveryDate = request.DeliveryDate
+	}
+
+	if request.WarrantyMonths != nil {
+		if *request.WarrantyMonths < 0 || *request.WarrantyMonths > maxWarrantyMonths {
+			return errInvalidWarrantyMonths
+		}
+		bid.WarrantyMonths = request.WarrantyMonths
+	}
+
+	if request.PaymentTerms != "" {
+		if !request.PaymentTerms.IsValid() {
+			return errInvalidPaymentTerms
+		}
+		bid.PaymentTerms = request.PaymentTerms
+	}
+
+	if bid.Amount != nil && bid.Currency == "" {
+		return errMissingCurrency
+	}
+	return nil
+}
+
+// bidSortValue возвращает значение поля сортировки для курсора в том же виде,
+// в каком его сравнивает выражение из bidSortOrder.
+func bidSortValue(bid models.Bid, sort sortOrder) string {
+	empty := strings.Trim(bidSortEmpty[sort.Key][bidSortDirection(sort)], "'")
+	switch sort.Key {
+	case "createdAt":
+		return bid.CreatedAt.Format(time.RFC3339Nano)
+	case "amount":
+		if bid.Amount == nil {
+			return empty
+		}
+		return strconv.FormatFloat(*bid.Amount, 'f', -1, 64)
+	case "deliveryDate":
+		if bid.DeliveryDate == nil {
+			return empty
+		}
+		return bid.DeliveryDate.Format(time.RFC3339Nano)
+	case "warrantyMonths":
+		if bid.WarrantyMonths == nil {
+			return empty
+		}
+		return strconv.Itoa(*bid.WarrantyMonths)
+	}
+	return bid.Name
+}
+
+// applyBidLineItems проверяет цены по позициям тендера и пересчитывает сумму
+// предложения. Отсутствующий в запросе lineItems оставляет позиции как есть,
+// пустой — удаляет их, после чего сумма задаётся полем amount.
+func applyBidLineItems(bid *models.Bid, request models.NewBidRequest, tender models.Tender) error {
+	if request.LineItems != nil {
+		known := make(map[uuid.UUID]bool, len(tender.LineItems))
+		for _, item := range tender.LineItems {
+			known[item.ID] = true
+		}
+
+		items := make(models.BidLineItems, len(request.LineItems))
+		seen := make(map[uuid.UUID]bool, len(request.LineItems))
+		for i, item := range request.LineItems {
+			if !known[item.LineItemID] {
+				return errUnknownLineItem
+			}
+			if seen[item.LineItemID] {
+				return errDuplicateBidLineItem
+			}
+			seen[item.LineItemID] = true
+			if item.Quantity <= 0 || item.UnitPrice <= 0 || item.Quantity*item.UnitPrice >= maxBudgetOrPrice {
+				return errInvalidBidLineItem
+			}
+			item.Total = roundMoney(item.Quantity * item.UnitPrice)
+			items[i] = item
+		}
+		bid.LineItems = items
+	}
+
+	if len(bid.LineItems) == 0 {
+		return nil
+	}
+	if request.Amount != nil {
+		return errAmountWithLineItems
+	}
+
+	var total float64
+	for _, item := range bid.LineItems {
+		total += item.Total
+	}
+	total = roundMoney(total)
+	if total >= maxBudgetOrPrice {
+		return errInvalidAmount
+	}
+	bid.Amount = &total
+	if bid.Currency == "" {
+		return errMissingCurrency
+	}
+	return nil
+}
+
+// roundMoney округляет сумму до копеек, как её хранит numeric(18,2).
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// checkBidStage проверяет, что квалификационная заявка не содержит цены.
+func checkBidStage(bid models.Bid) error {
+	if bid.Stage == models.STAGE_PREQUALIFICATION && (bid.Amount != nil || len(bid.LineItems) > 0) {
+		return errPriceInQualification
+	}
+	return nil
+}
+
+// isBidAuthor сообщает, что сотрудник — автор предложения: сам или как
+// ответственный организации-автора.
+func isBidAuthor(employee models.Employee, bid models.Bid) (bool, error) {
+	if bid.AuthorType == models.AUTHOR_USER {
+		return bid.AuthorID == employee.ID, nil
+	}
+	_, found, err := organizationRole(employee.ID, bid.AuthorID.String())
+	return found, err
+}
+
+// authorizeBidAuthor проверяет, что запрос пришёл от автора предложения.
+// Анонимный запрос получает 401, чужой — 403.
+func authorizeBidAuthor(w http.ResponseWriter, r *http.Request, bid models.Bid) (*models.Employee, bool) {
+	username := currentUsername(r)
+	if username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return nil, false
+	}
+
+	var employee models.Employee
+	if err := db.DB.Where("username = ?", username).First(&employee).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusUnauthorized)
+			errorResponse := models.NewErrorResponse("Пользователь не существует или некорректен.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return nil, false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return nil, false
+	}
+
+	author, err := isBidAuthor(employee, bid)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при проверке ответственных.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return nil, false
+	}
+	if !author {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := models.NewErrorResponse("Недостаточно прав для выполнения действия.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return nil, false
+	}
+	return &employee, true
+}
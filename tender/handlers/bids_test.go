@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateBidStatusValidation(t *testing.T) {
+	w := serve(t, http.MethodPut, "/api/bids/"+uuid.NewString()+"/status?status=APPROVED", nil, principal{})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("code = %d, want 400: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetBidsForTenderSealed(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	author := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	key := createAPIKey(t, organization, models.SCOPE_BIDS_READ)
+
+	tests := []struct {
+		name     string
+		deadline time.Duration
+		as       principal
+		wantBids int
+	}{
+		{"API-ключ до срока", time.Hour, key, 0},
+		{"автор до срока", time.Hour, asEmployee(author), 0},
+		{"API-ключ после срока", -time.Hour, key, 1},
+		{"автор после срока", -time.Hour, asEmployee(author), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadline := time.Now().Add(tt.deadline)
+			tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, func(tender *models.Tender) {
+				tender.Sealed = true
+				tender.SubmissionDeadline = &deadline
+			})
+			createBid(t, tender, author, models.BID_PUBLISHED, nil)
+
+			w := serve(t, http.MethodGet, "/api/bids/"+tender.ID.String()+"/list", nil, tt.as)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+			}
+			if total := w.Header().Get("X-Total-Count"); total != "1" {
+				t.Errorf("X-Total-Count = %q, want 1", total)
+			}
+			if sealed := w.Header().Get("X-Sealed-Until") != ""; sealed != (tt.wantBids == 0) {
+				t.Errorf("X-Sealed-Until = %q", w.Header().Get("X-Sealed-Until"))
+			}
+			var bids []models.BidResponse
+			decode(t, w, &bids)
+			if len(bids) != tt.wantBids {
+				t.Errorf("got %d bids, want %d", len(bids), tt.wantBids)
+			}
+		})
+	}
+}
+
+func TestEditBidConcurrent(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	author := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+	bid := createBid(t, tender, author, models.BID_CREATED, nil)
+
+	const edits = 8
+	codes := make([]int, edits)
+	var wg sync.WaitGroup
+	for i := 0; i < edits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serve(t, http.MethodPatch, "/api/bids/"+bid.ID.String()+"/edit", models.NewBidRequest{
+				Name:        fmt.Sprintf("Правка %d", i),
+				Description: "Описание",
+			}, asEmployee(author)).Code
+		}(i)
+	}
+	wg.Wait()
+
+	// Одновременная правка той же версии либо проходит, либо получает 409 — но не теряется молча
+	succeeded := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			succeeded++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("unexpected status %d", code)
+		}
+	}
+	if succeeded == 0 {
+		t.Fatal("no edit succeeded")
+	}
+	assertBidHistory(t, bid.ID, succeeded)
+}
+
+func TestRollbackBidConcurrent(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	author := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+	bid := createBid(t, tender, author, models.BID_CREATED, nil)
+
+	w := serve(t, http.MethodPatch, "/api/bids/"+bid.ID.String()+"/edit", models.NewBidRequest{Name: "Правка", Description: "Описание"}, asEmployee(author))
+	if w.Code != http.StatusOK {
+		t.Fatalf("edit: status = %d: %s", w.Code, w.Body.String())
+	}
+
+	const rollbacks = 8
+	codes := make([]int, rollbacks)
+	var wg sync.WaitGroup
+	for i := 0; i < rollbacks; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serve(t, http.MethodPut, "/api/bids/"+bid.ID.String()+"/rollback/1", nil, asEmployee(author)).Code
+		}(i)
+	}
+	wg.Wait()
+
+	for _, code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("rollback: status = %d", code)
+		}
+	}
+	assertBidHistory(t, bid.ID, 1+rollbacks)
+
+	var current models.Bid
+	if err := db.DB.First(&current, "id = ?", bid.ID).Error; err != nil {
+		t.Fatalf("reload bid: %v", err)
+	}
+	if current.Name != bid.Name {
+		t.Errorf("name = %q, want %q", current.Name, bid.Name)
+	}
+}
+
+// assertBidHistory проверяет, что после changes правок у предложения версия
+// 1+changes, а в истории ровно версии 1..changes без повторов.
+func assertBidHistory(t *testing.T, bidID uuid.UUID, changes int) {
+	t.Helper()
+
+	var current models.Bid
+	if err := db.DB.First(&current, "id = ?", bidID).Error; err != nil {
+		t.Fatalf("reload bid: %v", err)
+	}
+	if current.Version != uint(1+changes) {
+		t.Errorf("version = %d, want %d", current.Version, 1+changes)
+	}
+
+	var versions []uint
+	if err := db.DB.Model(&models.BidVersion{}).Where("bid_id = ?", bidID).Order("version").Pluck("version", &versions).Error; err != nil {
+		t.Fatalf("bid versions: %v", err)
+	}
+	if len(versions) != changes {
+		t.Fatalf("history has %d versions, want %d: %v", len(versions), changes, versions)
+	}
+	for i, version := range versions {
+		if version != uint(i+1) {
+			t.Fatalf("history versions = %v, want 1..%d", versions, changes)
+		}
+	}
+}
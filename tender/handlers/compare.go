@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// Веса по умолчанию: 70% цена, 30% срок поставки.
+var defaultComparisonWeights = models.ComparisonWeights{Price: 0.7, Delivery: 0.3}
+
+var errInvalidWeights = errors.New("Параметры price_weight и delivery_weight должны быть неотрицательными числами, хотя бы один больше нуля.")
+
+// authorizeTenderBids загружает тендер из пути и проверяет, что текущий
+// сотрудник или интеграция организации может сравнивать предложения по нему.
+// При закрытых торгах сравнение доступно только после срока подачи.
+func authorizeTenderBids(w http.ResponseWriter, r *http.Request) (models.Tender, bool) {
+	var tender models.Tender
+
+	tenderId, err := uuid.Parse(mux.Vars(r)["tenderId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, false
+	}
+
+	principal := auth.OrganizationFrom(r.Context())
+	username := currentUsername(r)
+	if username == "" && principal == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, false
+	}
+
+	if err := db.DB.First(&tender, "id = ?", tenderId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Тендер не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return tender, false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, false
+	}
+
+	if principal != nil {
+		if !principal.HasScope(models.SCOPE_BIDS_READ) {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("API-ключу не выдан scope bids:read.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return tender, false
+		}
+		if tender.OrganizationID.String() != principal.OrganizationID {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("Недостаточно прав для выполнения действия.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return tender, false
+		}
+	} else if _, ok := authorizeUsername(w, username, tender.OrganizationID.String(), models.PERMISSION_READ); !ok {
+		return tender, false
+	}
+
+	if bidsSealed(tender) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := models.NewErrorResponse("Предложения закрытых торгов скрыты до окончания срока подачи.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, false
+	}
+
+	return tender, true
+}
+
+// GetBidMatrixHandler возвращает матрицу «поставщики × позиции тендера»
+// по опубликованным предложениям.
+func GetBidMatrixHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, ok := authorizeTenderBids(w, r)
+	if !ok {
+		return
+	}
+
+	var bids []models.Bid
+	if err := db.DB.Where("tender_id = ? AND status = ? AND stage = ?", tender.ID, models.BID_PUBLISHED, models.STAGE_COMMERCIAL).Order("created_at ASC, id ASC").Find(&bids).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении заявок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	lineItems := tender.LineItems
+	if lineItems == nil {
+		lineItems = models.TenderLineItems{}
+	}
+
+	position := make(map[uuid.UUID]int, len(lineItems))
+	for i, item := range lineItems {
+		position[item.ID] = i
+	}
+
+	suppliers := make([]models.BidMatrixRow, len(bids))
+	for i, bid := range bids {
+		prices := make([]*models.BidLineItem, len(lineItems))
+		for _, item := range bid.LineItems {
+			// Позиции, удалённые из тендера после подачи предложения, в матрицу не попадают
+			if j, ok := position[item.LineItemID]; ok {
+				item := item
+				prices[j] = &item
+			}
+		}
+
+		suppliers[i] = models.BidMatrixRow{
+			BidID:         bid.ID.String(),
+			Name:          bid.Name,
+			AuthorType:    bid.AuthorType,
+			AuthorID:      bid.AuthorID.String(),
+			Currency:      bid.Currency,
+			Amount:        bid.Amount,
+			ExceedsBudget: exceedsBudget(bid, tender),
+			Prices:        prices,
+		}
+	}
+
+	response := models.BidMatrixResponse{
+		TenderID:  tender.ID.String(),
+		LineItems: lineItems,
+		Suppliers: suppliers,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseComparisonWeights разбирает price_weight и delivery_weight и нормирует их
+// так, чтобы сумма была равна 1. Без параметров используются веса по умолчанию.
+func parseComparisonWeights(r *http.Request) (models.ComparisonWeights, error) {
+	query := r.URL.Query()
+	if query.Get("price_weight") == "" && query.Get("delivery_weight") == "" {
+		return defaultComparisonWeights, nil
+	}
+
+	var weights models.ComparisonWeights
+	for param, weight := range map[string]*float64{"price_weight": &weights.Price, "delivery_weight": &weights.Delivery} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || math.IsInf(parsed, 0) {
+			return weights, errInvalidWeights
+		}
+		*weight = parsed
+	}
+
+	sum := weights.Price + weights.Delivery
+	if sum <= 0 {
+		return weights, errInvalidWeights
+	}
+	weights.Price /= sum
+	weights.Delivery /= sum
+	return weights, nil
+}
+
+// scale переводит значение в балл от 0 до 100, где best — лучшее значение
+// среди предложений, worst — худшее. Если все значения равны, балл 100.
+func scale(value, best, worst float64) float64 {
+	if best == worst {
+		return 100
+	}
+	return 100 * (worst - value) / (worst - best)
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
+
+// GetBidComparisonHandler сравнивает опубликованные предложения по цене и сроку
+// поставки и ранжирует их по взвешенному баллу. Цены сравниваются и места
+// присваиваются только внутри одной валюты; предложение без цены или срока
+// получает по этому критерию 0 баллов.
+func GetBidComparisonHandler(w http.ResponseWriter, r *http.Request) {
+
+	weights, err := parseComparisonWeights(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	tender, ok := authorizeTenderBids(w, r)
+	if !ok {
+		return
+	}
+
+	var bids []models.Bid
+	if err := db.DB.Where("tender_id = ? AND status = ? AND stage = ?", tender.ID, models.BID_PUBLISHED, models.STAGE_COMMERCIAL).Order("created_at ASC, id ASC").Find(&bids).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении заявок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Лучшая и худшая цена в каждой валюте, самый ранний и самый поздний срок поставки
+	type bounds struct{ min, max float64 }
+	prices := make(map[string]bounds)
+	var deliveries *bounds
+	for _, bid := range bids {
+		if bid.Amount != nil {
+			b, ok := prices[bid.Currency]
+			if !ok {
+				b = bounds{*bid.Amount, *bid.Amount}
+			}
+			prices[bid.Currency] = bounds{math.Min(b.min, *bid.Amount), math.Max(b.max, *bid.Amount)}
+		}
+		if bid.DeliveryDate != nil {
+			at := float64(bid.DeliveryDate.Unix())
+			if deliveries == nil {
+				deliveries = &bounds{at, at}
+			}
+			deliveries.min = math.Min(deliveries.min, at)
+			deliveries.max = math.Max(deliveries.max, at)
+		}
+	}
+
+	rows := make([]models.BidComparisonRow, len(bids))
+	for i, bid := range bids {
+		row := models.BidComparisonRow{
+			BidID:          bid.ID.String(),
+			Name:           bid.Name,
+			AuthorType:     bid.AuthorType,
+			AuthorID:       bid.AuthorID.String(),
+			Amount:         bid.Amount,
+			Currency:       bid.Currency,
+			DeliveryDate:   bid.DeliveryDate,
+			WarrantyMonths: bid.WarrantyMonths,
+			PaymentTerms:   bid.PaymentTerms,
+			ExceedsBudget:  exceedsBudget(bid, tender),
+		}
+		if bid.Amount != nil {
+			b := prices[bid.Currency]
+			row.PriceScore = roundScore(scale(*bid.Amount, b.min, b.max))
+		}
+		if bid.DeliveryDate != nil {
+			row.DeliveryScore = roundScore(scale(float64(bid.DeliveryDate.Unix()), deliveries.min, deliveries.max))
+		}
+		row.Score = roundScore(weights.Price*row.PriceScore + weights.Delivery*row.DeliveryScore)
+		rows[i] = row
+	}
+
+	// Баллы за цену в разных валютах несравнимы, поэтому предложения ранжируются
+	// внутри своей валюты; предложения без цены образуют отдельную группу.
+	group := func(row models.BidComparisonRow) string {
+		if row.Amount == nil {
+			return ""
+		}
+		return row.Currency
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if group(rows[i]) != group(rows[j]) {
+			return group(rows[i]) < group(rows[j])
+		}
+		return rows[i].Score > rows[j].Score
+	})
+	for i := range rows {
+		rows[i].Rank = 1
+		if i > 0 && group(rows[i]) == group(rows[i-1]) {
+			rows[i].Rank = rows[i-1].Rank + 1
+		}
+	}
+
+	response := models.BidComparisonResponse{
+		TenderID: tender.ID.String(),
+		Weights:  weights,
+		Bids:     rows,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
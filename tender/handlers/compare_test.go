@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"tender/models"
+)
+
+func TestGetBidComparisonRanksPerCurrency(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	supplier := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+
+	priced := func(amount float64, currency string) func(*models.Bid) {
+		return func(bid *models.Bid) {
+			bid.Amount = &amount
+			bid.Currency = currency
+		}
+	}
+	createBid(t, tender, supplier, models.BID_PUBLISHED, priced(100, "RUB"))
+	createBid(t, tender, supplier, models.BID_PUBLISHED, priced(200, "RUB"))
+	createBid(t, tender, supplier, models.BID_PUBLISHED, priced(5, "USD"))
+
+	w := serve(t, http.MethodGet, "/api/tenders/"+tender.ID.String()+"/bids/compare?price_weight=1", nil, asEmployee(owner))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var response models.BidComparisonResponse
+	decode(t, w, &response)
+
+	ranks := make(map[string][]int)
+	for _, row := range response.Bids {
+		ranks[row.Currency] = append(ranks[row.Currency], row.Rank)
+	}
+	if got := ranks["RUB"]; len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("RUB ranks = %v, want [1 2]", got)
+	}
+	if got := ranks["USD"]; len(got) != 1 || got[0] != 1 {
+		t.Errorf("USD ranks = %v, want [1]", got)
+	}
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"tender/audit"
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxQuorum — сколько согласий approver достаточно для принятия предложения,
+// если согласующих в организации больше.
+const maxQuorum = 3
+
+var (
+	errTenderNotPublished = errors.New("Решение можно принять только по опубликованному тендеру.")
+	errBidDecided         = errors.New("По предложению уже принято итоговое решение.")
+)
+
+// decisionQuorum возвращает, сколько согласий нужно для принятия предложения,
+// и учитываются ли согласия только сотрудников с ролью approver. Кворум —
+// min(3, число approver). Если согласующих в организации нет, достаточно
+// одного согласия любого ответственного с правом согласования.
+func decisionQuorum(tx *gorm.DB, organizationID uuid.UUID) (quorum int64, approversOnly bool, err error) {
+	var approvers int64
+	err = tx.Model(&models.OrganizationResponsible{}).
+		Where("organization_id = ? AND role = ?", organizationID, models.ROLE_APPROVER).
+		Count(&approvers).Error
+	if err != nil {
+		return 0, false, err
+	}
+	if approvers == 0 {
+		return 1, false, nil
+	}
+	if approvers > maxQuorum {
+		return maxQuorum, true, nil
+	}
+	return approvers, true, nil
+}
+
+// countApprovals считает согласия по предложению; при approversOnly —
+// только от текущих approver организации тендера.
+func countApprovals(tx *gorm.DB, bidID, organizationID uuid.UUID, approversOnly bool) (int64, error) {
+	query := tx.Model(&models.BidDecision{}).
+		Where("bid_decisions.bid_id = ? AND bid_decisions.decision = ? AND bid_decisions.responsible_id IS NOT NULL", bidID, models.DECISION_APPROVED)
+	if approversOnly {
+		query = query.
+			Joins("JOIN organization_responsibles ON organization_responsibles.user_id = bid_decisions.responsible_id").
+			Where("organization_responsibles.organization_id = ? AND organization_responsibles.role = ?", organizationID, models.ROLE_APPROVER)
+	}
+	var approvals int64
+	err := query.Count(&approvals).Error
+	return approvals, err
+}
+
+// SubmitBidDecisionHandler принимает решение ответственного по предложению.
+// Любой отказ отклоняет предложение. Когда число согласий достигает кворума,
+// предложение принимается, а тендер закрывается. API-ключ со scope
+// decisions:write действует от имени всей организации, поэтому его решение
+// окончательное без кворума.
+func SubmitBidDecisionHandler(w http.ResponseWriter, r *http.Request) {
+
+	bidId, err := uuid.Parse(mux.Vars(r)["bidId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var decision models.Decision
+	switch strings.ToLower(r.URL.Query().Get("decision")) {
+	case "approved":
+		decision = models.DECISION_APPROVED
+	case "rejected":
+		decision = models.DECISION_REJECTED
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Допустимые decision: Approved, Rejected.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	principal := auth.OrganizationFrom(r.Context())
+	if principal != nil && !principal.HasScope(models.SCOPE_DECISIONS_WRITE) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := models.NewErrorResponse("API-ключу не выдан scope decisions:write.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	username := currentUsername(r)
+	if principal == nil && username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var bid models.Bid
+	if err := db.DB.First(&bid, "id = ?", bidId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Предложение не найдено.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var tender models.Tender
+	if err := db.DB.First(&tender, "id = ?", bid.TenderID).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	vote := models.BidDecision{Decision: decision}
+	if principal != nil {
+		if principal.OrganizationID != tender.OrganizationID.String() {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("API-ключ не принадлежит организации тендера.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		keyID := uuid.MustParse(principal.KeyID)
+		vote.APIKeyID = &keyID
+	} else {
+		responsible, ok := authorizeUsername(w, username, tender.OrganizationID.String(), models.PERMISSION_DECIDE_BIDS)
+		if !ok {
+			return
+		}
+		vote.ResponsibleID = &responsible.ID
+	}
+
+	if bid.Status != models.BID_PUBLISHED {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Решение можно принять только по опубликованному предложению.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if bidsSealed(tender) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := models.NewErrorResponse("Предложения закрытых торгов скрыты до окончания срока подачи.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Решения по тендеру принимаются по очереди под блокировкой тендера,
+	// иначе два одновременных согласия могут не увидеть друг друга и не закрыть кворум.
+	before, beforeTender := bid, tender
+	closed := false
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tender, "id = ?", bid.TenderID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&bid, "id = ?", bid.ID).Error; err != nil {
+			return err
+		}
+		before, beforeTender = bid, tender
+		if tender.Status != models.TENDER_PUBLISHED {
+			return errTenderNotPublished
+		}
+		if bid.Decision != "" {
+			return errBidDecided
+		}
+
+		vote.ID = uuid.New()
+		vote.BidID = bid.ID
+		vote.CreatedAt = time.Now()
+		if err := tx.Omit("Bid", "Responsible", "APIKey").Create(&vote).Error; err != nil {
+			return err
+		}
+
+		if decision == models.DECISION_APPROVED && vote.ResponsibleID != nil {
+			quorum, approversOnly, err := decisionQuorum(tx, tender.OrganizationID)
+			if err != nil {
+				return err
+			}
+			approvals, err := countApprovals(tx, bid.ID, tender.OrganizationID, approversOnly)
+			if err != nil {
+				return err
+			}
+			if approvals < quorum {
+				return nil
+			}
+		}
+
+		bid.Decision = decision
+		if err := tx.Model(&bid).Update("decision", decision).Error; err != nil {
+			return err
+		}
+		if decision == models.DECISION_REJECTED {
+			return nil
+		}
+
+		tenderVersion := tender.Snapshot()
+		if err := tx.Omit("Tender").Create(&tenderVersion).Error; err != nil {
+			return err
+		}
+		tender.Status = models.TENDER_CLOSED
+		if vote.ResponsibleID != nil {
+			tender.UpdatedByID = vote.ResponsibleID
+		}
+		tender.Version++
+		closed = true
+		return tx.Omit("Organization").Save(&tender).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errTenderNotPublished):
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		case errors.Is(err, errBidDecided):
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			w.WriteHeader(http.StatusConflict)
+			errorResponse := models.NewErrorResponse("Вы уже приняли решение по этому предложению.")
+			json.NewEncoder(w).Encode(errorResponse)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("Ошибка при сохранении решения по предложению: %v", err)
+			errorResponse := models.NewErrorResponse("Ошибка при сохранении решения по предложению.")
+			json.NewEncoder(w).Encode(errorResponse)
+		}
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionDecision, models.ENTITY_BID, bid.ID.String(), tender.OrganizationID.String(), before, bid)
+	if closed {
+		audit.Record(r.Context(), audit.ActionStatusChange, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), beforeTender, tender)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(bidResponseWithBudget(bid))
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+)
+
+func TestSubmitBidDecisionAPIKeyScope(t *testing.T) {
+	as := principal{organization: &auth.OrganizationPrincipal{
+		OrganizationID: uuid.NewString(),
+		KeyID:          uuid.NewString(),
+		Scopes:         []models.APIKeyScope{models.SCOPE_BIDS_READ},
+	}}
+
+	w := serve(t, http.MethodPut, "/api/bids/"+uuid.NewString()+"/submit_decision?decision=Approved", nil, as)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
+
+func TestSubmitBidDecision(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	approvers := []models.Employee{createEmployee(t), createEmployee(t)}
+	author := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{
+		&owner:        models.ROLE_OWNER,
+		&approvers[0]: models.ROLE_APPROVER,
+		&approvers[1]: models.ROLE_APPROVER,
+	})
+	other := createOrganization(t, nil)
+
+	decide := func(bid models.Bid, decision string, as principal) int {
+		t.Helper()
+		return serve(t, http.MethodPut, "/api/bids/"+bid.ID.String()+"/submit_decision?decision="+decision, nil, as).Code
+	}
+	tenderStatus := func(tender models.Tender) models.TenderStatus {
+		t.Helper()
+		if err := db.DB.First(&tender, "id = ?", tender.ID).Error; err != nil {
+			t.Fatalf("reload tender: %v", err)
+		}
+		return tender.Status
+	}
+
+	t.Run("кворум approver", func(t *testing.T) {
+		tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+		bid := createBid(t, tender, author, models.BID_PUBLISHED, nil)
+
+		// Согласие owner не засчитывается, пока в организации есть approver
+		if code := decide(bid, "Approved", asEmployee(owner)); code != http.StatusOK {
+			t.Fatalf("owner: status = %d", code)
+		}
+		if code := decide(bid, "Approved", asEmployee(approvers[0])); code != http.StatusOK {
+			t.Fatalf("approver 1: status = %d", code)
+		}
+		if status := tenderStatus(tender); status != models.TENDER_PUBLISHED {
+			t.Fatalf("tender closed before quorum: %s", status)
+		}
+		if code := decide(bid, "Approved", asEmployee(approvers[0])); code != http.StatusConflict {
+			t.Fatalf("repeated vote: status = %d, want 409", code)
+		}
+		if code := decide(bid, "Approved", asEmployee(approvers[1])); code != http.StatusOK {
+			t.Fatalf("approver 2: status = %d", code)
+		}
+		if status := tenderStatus(tender); status != models.TENDER_CLOSED {
+			t.Fatalf("tender status = %s, want CLOSED", status)
+		}
+	})
+
+	t.Run("автор предложения без прав", func(t *testing.T) {
+		tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+		bid := createBid(t, tender, author, models.BID_PUBLISHED, nil)
+
+		if code := decide(bid, "Approved", asEmployee(author)); code != http.StatusForbidden {
+			t.Fatalf("status = %d, want 403", code)
+		}
+	})
+
+	t.Run("API-ключ чужой организации", func(t *testing.T) {
+		tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+		bid := createBid(t, tender, author, models.BID_PUBLISHED, nil)
+
+		if code := decide(bid, "Approved", createAPIKey(t, other, models.SCOPE_DECISIONS_WRITE)); code != http.StatusForbidden {
+			t.Fatalf("status = %d, want 403", code)
+		}
+	})
+
+	t.Run("API-ключ решает без кворума", func(t *testing.T) {
+		tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+		bid := createBid(t, tender, author, models.BID_PUBLISHED, nil)
+
+		if code := decide(bid, "Approved", createAPIKey(t, organization, models.SCOPE_DECISIONS_WRITE)); code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", code)
+		}
+		if status := tenderStatus(tender); status != models.TENDER_CLOSED {
+			t.Fatalf("tender status = %s, want CLOSED", status)
+		}
+	})
+}
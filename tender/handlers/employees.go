@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"tender/db"
+	"tender/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+var sortByUsername = sortOrder{Key: "username", Column: "username"}
+
+func GetEmployeesHandler(w http.ResponseWriter, r *http.Request) {
+
+	pg, err := parsePage(r, sortByUsername)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	query := db.DB.Model(&models.Employee{}).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователей.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var employees []models.Employee
+	if err := pg.apply(query).Find(&employees).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователей.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var last pageCursor
+	if len(employees) > 0 {
+		employee := employees[len(employees)-1]
+		last = pg.cursor(employee.Username, employee.ID)
+	}
+
+	writePageHeaders(w, pg, total, len(employees), last)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(employees)
+}
+
+func CreateEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+
+	var newEmployeeRequest models.NewEmployeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&newEmployeeRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if newEmployeeRequest.Username == "" || len(newEmployeeRequest.Username) > 50 || len(newEmployeeRequest.FirstName) > 50 || len(newEmployeeRequest.LastName) > 50 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поле username обязательно, username, firstName и lastName не длиннее 50 символов."))
+		return
+	}
+
+	var count int64
+	if err := db.DB.Model(&models.Employee{}).Where("username = ?", newEmployeeRequest.Username).Count(&count).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка при проверке пользователя."))
+		return
+	}
+	if count > 0 {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Пользователь с таким username уже существует."))
+		return
+	}
+
+	employee := models.Employee{
+		ID:        uuid.New(),
+		Username:  newEmployeeRequest.Username,
+		FirstName: newEmployeeRequest.FirstName,
+		LastName:  newEmployeeRequest.LastName,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := db.DB.Create(&employee).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при создании пользователя: %v", err)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка при создании пользователя."))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(employee)
+}
+
+func GetEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+
+	employeeId, err := uuid.Parse(mux.Vars(r)["employeeId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var employee models.Employee
+	if err := db.DB.First(&employee, "id = ?", employeeId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Пользователь не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(employee)
+}
+
+func EditEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+
+	employeeId, err := uuid.Parse(mux.Vars(r)["employeeId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var updateData models.NewEmployeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateData); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if len(updateData.Username) > 50 || len(updateData.FirstName) > 50 || len(updateData.LastName) > 50 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поля username, firstName и lastName не длиннее 50 символов."))
+		return
+	}
+
+	var employee models.Employee
+	if err := db.DB.First(&employee, "id = ?", employeeId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Пользователь не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if updateData.Username != "" && updateData.Username != employee.Username {
+		var count int64
+		if err := db.DB.Model(&models.Employee{}).Where("username = ?", updateData.Username).Count(&count).Error; err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка при проверке пользователя."))
+			return
+		}
+		if count > 0 {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Пользователь с таким username уже существует."))
+			return
+		}
+		employee.Username = updateData.Username
+	}
+	if updateData.FirstName != "" {
+		employee.FirstName = updateData.FirstName
+	}
+	if updateData.LastName != "" {
+		employee.LastName = updateData.LastName
+	}
+	employee.UpdatedAt = time.Now()
+
+	if err := db.DB.Save(&employee).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при обновлении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(employee)
+}
+
+func DeleteEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+
+	employeeId, err := uuid.Parse(mux.Vars(r)["employeeId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	result := db.DB.Delete(&models.Employee{}, "id = ?", employeeId)
+	if result.Error != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при удалении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if result.RowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := models.NewErrorResponse("Пользователь не найден.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"tender/audit"
+	"tender/db"
+	"tender/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxCriteria = 20
+	maxScore    = 10
+)
+
+var (
+	errCriteriaScored   = errors.New("Критерии нельзя менять после начала оценки предложений.")
+	errUnknownCriterion = errors.New("Каждый criterionId должен быть критерием этого тендера и встречаться один раз.")
+)
+
+func SetEvaluationCriteriaHandler(w http.ResponseWriter, r *http.Request) {
+
+	tenderId, err := uuid.Parse(mux.Vars(r)["tenderId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	username := currentUsername(r)
+	if username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var tender models.Tender
+	if err := db.DB.First(&tender, "id = ?", tenderId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Тендер не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if _, ok := authorizeUsername(w, username, tender.OrganizationID.String(), models.PERMISSION_EDIT_TENDERS); !ok {
+		return
+	}
+
+	var requests []models.NewEvaluationCriterionRequest
+	if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if len(requests) == 0 || len(requests) > maxCriteria {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Нужно от 1 до 20 критериев оценки."))
+		return
+	}
+
+	criteria := make([]models.EvaluationCriterion, len(requests))
+	seen := make(map[string]bool, len(requests))
+	for i, request := range requests {
+		if request.Name == "" || len(request.Name) > 100 || request.Weight <= 0 || seen[request.Name] {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("У каждого критерия обязательны уникальное name (до 100 символов) и weight больше нуля."))
+			return
+		}
+		seen[request.Name] = true
+
+		criteria[i] = models.EvaluationCriterion{
+			ID:        uuid.New(),
+			TenderID:  tender.ID,
+			Name:      request.Name,
+			Weight:    request.Weight,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	var previous []models.EvaluationCriterion
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокировка тендера не даёт оценкам появиться между проверкой и заменой критериев
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Tender{}, "id = ?", tender.ID).Error; err != nil {
+			return err
+		}
+
+		// После первой оценки критерии фиксируются, иначе баллы потеряют смысл
+		var scored int64
+		if err := tx.Model(&models.BidScore{}).
+			Where("criterion_id IN (SELECT id FROM evaluation_criteria WHERE tender_id = ?)", tender.ID).
+			Count(&scored).Error; err != nil {
+			return err
+		}
+		if scored > 0 {
+			return errCriteriaScored
+		}
+
+		if err := tx.Clauses(clause.Returning{}).Where("tender_id = ?", tender.ID).Delete(&previous).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Tender").Create(&criteria).Error
+	})
+	if errors.Is(err, errCriteriaScored) {
+		w.WriteHeader(http.StatusConflict)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при сохранении критериев оценки: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при сохранении критериев оценки.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionCriteria, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), previous, criteria)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(criteria)
+}
+
+func GetEvaluationCriteriaHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Критерии видят те, кому виден тендер: черновик — только организация,
+	// тендер по приглашениям — только приглашённые
+	tender, _, _, ok := loadVisibleTender(w, r)
+	if !ok {
+		return
+	}
+
+	var criteria []models.EvaluationCriterion
+	if err := db.DB.Where("tender_id = ?", tender.ID).Order("created_at ASC, name ASC").Find(&criteria).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении критериев оценки.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(criteria)
+}
+
+func SubmitBidScoresHandler(w http.ResponseWriter, r *http.Request) {
+
+	bidId, err := uuid.Parse(mux.Vars(r)["bidId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	username := currentUsername(r)
+	if username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var bid models.Bid
+	if err := db.DB.First(&bid, "id = ?", bidId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Предложение не найдено.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var tender models.Tender
+	if err := db.DB.First(&tender, "id = ?", bid.TenderID).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Оценивают те же ответственные, что согласуют предложения
+	evaluator, ok := authorizeUsername(w, username, tender.OrganizationID.String(), models.PERMISSION_DECIDE_BIDS)
+	if !ok {
+		return
+	}
+
+	// Оцениваются только предложения, которые попадают в итоговый рейтинг
+	if bid.Status != models.BID_PUBLISHED || bid.Stage != models.STAGE_COMMERCIAL {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Оценивать можно только опубликованные предложения коммерческого этапа.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if bidsSealed(tender) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := models.NewErrorResponse("Предложения закрытых торгов скрыты до окончания срока подачи.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var requests []models.BidScoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if len(requests) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Передайте баллы хотя бы по одному критерию."))
+		return
+	}
+
+	scores := make([]models.BidScore, len(requests))
+	seen := make(map[uuid.UUID]bool, len(requests))
+	for i, request := range requests {
+		criterionId, err := uuid.Parse(request.CriterionID)
+		if err != nil || seen[criterionId] {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(errUnknownCriterion.Error()))
+			return
+		}
+		seen[criterionId] = true
+
+		if request.Score < 0 || request.Score > maxScore {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Балл по критерию должен быть от 0 до 10."))
+			return
+		}
+
+		scores[i] = models.BidScore{
+			ID:          uuid.New(),
+			BidID:       bid.ID,
+			CriterionID: criterionId,
+			EvaluatorID: evaluator.ID,
+			Score:       request.Score,
+			UpdatedAt:   time.Now(),
+		}
+	}
+
+	var previous []models.BidScore
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// Разделяемая блокировка тендера ждёт замены критериев, которая берёт эксклюзивную
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).First(&models.Tender{}, "id = ?", tender.ID).Error; err != nil {
+			return err
+		}
+
+		var criteria []models.EvaluationCriterion
+		if err := tx.Where("tender_id = ?", tender.ID).Find(&criteria).Error; err != nil {
+			return err
+		}
+		known := make(map[uuid.UUID]bool, len(criteria))
+		for _, criterion := range criteria {
+			known[criterion.ID] = true
+		}
+		for _, score := range scores {
+			if !known[score.CriterionID] {
+				return errUnknownCriterion
+			}
+		}
+
+		if err := tx.Where("bid_id = ? AND evaluator_id = ?", bid.ID, evaluator.ID).Find(&previous).Error; err != nil {
+			return err
+		}
+
+		return tx.Omit("Bid", "Criterion", "Evaluator").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "bid_id"}, {Name: "criterion_id"}, {Name: "evaluator_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"score", "updated_at"}),
+		}).Create(&scores).Error
+	})
+	if errors.Is(err, errUnknownCriterion) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при сохранении оценок: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при сохранении оценок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var saved []models.BidScore
+	if err := db.DB.Where("bid_id = ? AND evaluator_id = ?", bid.ID, evaluator.ID).Find(&saved).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении оценок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionScore, models.ENTITY_BID, bid.ID.String(), tender.OrganizationID.String(), previous, saved)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// GetTenderEvaluationHandler сводит баллы ответственных в итоговый рейтинг:
+// по каждому критерию берётся средний балл оценивших, итог — среднее
+// взвешенное по весам критериев. Неоценённый критерий даёт 0 баллов.
+func GetTenderEvaluationHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, ok := authorizeTenderBids(w, r)
+	if !ok {
+		return
+	}
+
+	var criteria []models.EvaluationCriterion
+	if err := db.DB.Where("tender_id = ?", tender.ID).Order("created_at ASC, name ASC").Find(&criteria).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении критериев оценки.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var bids []models.Bid
+	if err := db.DB.Where("tender_id = ? AND status = ? AND stage = ?", tender.ID, models.BID_PUBLISHED, models.STAGE_COMMERCIAL).Order("created_at ASC, id ASC").Find(&bids).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении заявок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var scores []models.BidScore
+	if err := db.DB.Where("bid_id IN (SELECT id FROM bids WHERE tender_id = ? AND status = ? AND stage = ?)", tender.ID, models.BID_PUBLISHED, models.STAGE_COMMERCIAL).Find(&scores).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении оценок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	type key struct{ bid, criterion uuid.UUID }
+	sums := make(map[key]float64)
+	counts := make(map[key]int)
+	evaluators := make(map[uuid.UUID]map[uuid.UUID]bool)
+	for _, score := range scores {
+		k := key{score.BidID, score.CriterionID}
+		sums[k] += score.Score
+		counts[k]++
+		if evaluators[score.BidID] == nil {
+			evaluators[score.BidID] = make(map[uuid.UUID]bool)
+		}
+		evaluators[score.BidID][score.EvaluatorID] = true
+	}
+
+	var totalWeight float64
+	for _, criterion := range criteria {
+		totalWeight += criterion.Weight
+	}
+
+	results := make([]models.BidEvaluationResult, len(bids))
+	for i, bid := range bids {
+		result := models.BidEvaluationResult{
+			BidID:      bid.ID.String(),
+			Name:       bid.Name,
+			AuthorType: bid.AuthorType,
+			AuthorID:   bid.AuthorID.String(),
+			Evaluators: len(evaluators[bid.ID]),
+			Criteria:   make([]models.CriterionResult, len(criteria)),
+		}
+
+		var weighted float64
+		for j, criterion := range criteria {
+			k := key{bid.ID, criterion.ID}
+			var average float64
+			if counts[k] > 0 {
+				average = sums[k] / float64(counts[k])
+			}
+			weighted += criterion.Weight * average
+
+			result.Criteria[j] = models.CriterionResult{
+				CriterionID:  criterion.ID.String(),
+				Name:         criterion.Name,
+				Weight:       criterion.Weight,
+				AverageScore: roundScore(average),
+			}
+		}
+		if totalWeight > 0 {
+			result.FinalScore = roundScore(weighted / totalWeight)
+		}
+		results[i] = result
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].FinalScore > results[j].FinalScore
+	})
+	for i := range results {
+		results[i].Rank = i + 1
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"tender/models"
+)
+
+func TestSubmitBidScores(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	supplier := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+
+	w := serve(t, http.MethodPut, "/api/tenders/"+tender.ID.String()+"/criteria", []models.NewEvaluationCriterionRequest{{Name: "Качество", Weight: 1}}, asEmployee(owner))
+	if w.Code != http.StatusOK {
+		t.Fatalf("criteria: status = %d: %s", w.Code, w.Body.String())
+	}
+	var criteria []models.EvaluationCriterion
+	decode(t, w, &criteria)
+	scores := []models.BidScoreRequest{{CriterionID: criteria[0].ID.String(), Score: 8}}
+
+	tests := []struct {
+		name   string
+		status models.BidStatus
+		stage  models.TenderStage
+		want   int
+	}{
+		{"опубликованное коммерческое", models.BID_PUBLISHED, models.STAGE_COMMERCIAL, http.StatusOK},
+		{"квалификационная заявка", models.BID_PUBLISHED, models.STAGE_PREQUALIFICATION, http.StatusBadRequest},
+		{"отменённое", models.BID_CANCELED, models.STAGE_COMMERCIAL, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bid := createBid(t, tender, supplier, tt.status, func(bid *models.Bid) { bid.Stage = tt.stage })
+
+			w := serve(t, http.MethodPut, "/api/bids/"+bid.ID.String()+"/scores", scores, asEmployee(owner))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	// После первой оценки критерии заморожены
+	w = serve(t, http.MethodPut, "/api/tenders/"+tender.ID.String()+"/criteria", []models.NewEvaluationCriterionRequest{{Name: "Цена", Weight: 1}}, asEmployee(owner))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("criteria after scores: status = %d, want 409: %s", w.Code, w.Body.String())
+	}
+}
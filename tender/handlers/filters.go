@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tender/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var tenderSortColumns = map[string]string{
+	"name":      "name",
+	"createdAt": "created_at",
+	"version":   "version",
+}
+
+var (
+	errInvalidServiceType  = errors.New("Неверное значение параметра service_type.")
+	errInvalidStatus       = errors.New("Неверное значение параметра status.")
+	errInvalidOrganization = errors.New("Неверный формат параметра organization_id.")
+	errInvalidCreatedFrom  = errors.New("Неверный формат параметра created_from, ожидается RFC3339.")
+	errInvalidCreatedTo    = errors.New("Неверный формат параметра created_to, ожидается RFC3339.")
+)
+
+// tenderFilter — условия отбора для GET /api/tenders.
+type tenderFilter struct {
+	ServiceTypes   []models.TenderServiceType
+	Statuses       []models.TenderStatus
+	OrganizationID string
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	Search         string
+}
+
+func parseTenderFilter(r *http.Request) (tenderFilter, error) {
+	var f tenderFilter
+	query := r.URL.Query()
+
+	for _, value := range query["service_type"] {
+		serviceType := models.TenderServiceType(strings.ToUpper(value))
+		if !serviceType.IsValid() {
+			return f, errInvalidServiceType
+		}
+		f.ServiceTypes = append(f.ServiceTypes, serviceType)
+	}
+
+	for _, value := range query["status"] {
+		status := models.TenderStatus(strings.ToUpper(value))
+		if !status.IsValid() {
+			return f, errInvalidStatus
+		}
+		f.Statuses = append(f.Statuses, status)
+	}
+
+	if organizationID := query.Get("organization_id"); organizationID != "" {
+		if _, err := uuid.Parse(organizationID); err != nil {
+			return f, errInvalidOrganization
+		}
+		f.OrganizationID = organizationID
+	}
+
+	if createdFrom := query.Get("created_from"); createdFrom != "" {
+		t, err := time.Parse(time.RFC3339, createdFrom)
+		if err != nil {
+			return f, errInvalidCreatedFrom
+		}
+		f.CreatedFrom = &t
+	}
+
+	if createdTo := query.Get("created_to"); createdTo != "" {
+		t, err := time.Parse(time.RFC3339, createdTo)
+		if err != nil {
+			return f, errInvalidCreatedTo
+		}
+		f.CreatedTo = &t
+	}
+
+	f.Search = strings.TrimSpace(query.Get("search"))
+
+	return f, nil
+}
+
+func (f tenderFilter) apply(query *gorm.DB) *gorm.DB {
+	if len(f.ServiceTypes) > 0 {
+		query = query.Where("service_type IN ?", f.ServiceTypes)
+	}
+	if len(f.Statuses) > 0 {
+		query = query.Where("status IN ?", f.Statuses)
+	}
+	if f.OrganizationID != "" {
+		query = query.Where("organization_id = ?", f.OrganizationID)
+	}
+	if f.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		query = query.Where("created_at < ?", *f.CreatedTo)
+	}
+	if f.Search != "" {
+		pattern := "%" + escapeLike(f.Search) + "%"
+		query = query.Where("(name ILIKE ? OR description ILIKE ?)", pattern, pattern)
+	}
+	return query
+}
+
+// tenderSortValue возвращает значение поля сортировки тендера для курсора.
+func tenderSortValue(tender models.Tender, key string) string {
+	switch key {
+	case "createdAt":
+		return tender.CreatedAt.Format(time.RFC3339Nano)
+	case "version":
+		return strconv.FormatUint(uint64(tender.Version), 10)
+	}
+	return tender.Name
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -2,18 +2,21 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
+	"tender/audit"
+	"tender/auth"
 	"tender/db"
 	"tender/models"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func PingHandler(w http.ResponseWriter, r *http.Request) {
@@ -51,92 +54,209 @@ func CreateTenderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	re := regexp.MustCompile(`[^\x00-\x7F]+`)
-	cleanBody := re.ReplaceAllString(string(body), "")
-
-	if err := json.Unmarshal([]byte(cleanBody), &newTenderRequest); err != nil {
+	if err := json.Unmarshal(body, &newTenderRequest); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
 		return
 	}
 
-	if newTenderRequest.Name == "" || newTenderRequest.Description == "" || newTenderRequest.ServiceType == "" || newTenderRequest.OrganizationID == "" || newTenderRequest.CreatorUsername == "" {
+	// Аутентифицированный сотрудник может создавать тендеры только от своего имени
+	if employee := auth.EmployeeFrom(r.Context()); employee != nil {
+		if newTenderRequest.CreatorUsername != "" && newTenderRequest.CreatorUsername != employee.Username {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Поле creatorUsername не совпадает с текущим пользователем."))
+			return
+		}
+		newTenderRequest.CreatorUsername = employee.Username
+	}
+
+	// Интеграция с API-ключом создаёт тендеры от имени своей организации без creatorUsername
+	principal := auth.OrganizationFrom(r.Context())
+	if principal != nil {
+		if !principal.HasScope(models.SCOPE_TENDERS_WRITE) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("API-ключу не выдан scope tenders:write."))
+			return
+		}
+		if newTenderRequest.OrganizationID != "" && newTenderRequest.OrganizationID != principal.OrganizationID {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("API-ключ не принадлежит организации organizationId."))
+			return
+		}
+		newTenderRequest.OrganizationID = principal.OrganizationID
+	}
+
+	// Без токена и API-ключа автору из тела запроса верим только в режиме совместимости
+	if principal == nil && auth.EmployeeFrom(r.Context()) == nil && !auth.LegacyUsernameEnabled() {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Пользователь не аутентифицирован."))
+		return
+	}
+
+	if newTenderRequest.Name == "" || newTenderRequest.Description == "" || newTenderRequest.ServiceType == "" || newTenderRequest.OrganizationID == "" || (newTenderRequest.CreatorUsername == "" && principal == nil) {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.NewErrorResponse("Поля name, description, organizationId и creatorUsername обязательны. Возможно поле serviceType неправильно заполнено."))
 		return
 	}
+
+	organizationId, err := uuid.Parse(newTenderRequest.OrganizationID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Неверный формат идентификатора организации."))
+		return
+	}
+
+	if newTenderRequest.SubmissionDeadline != nil && !newTenderRequest.SubmissionDeadline.After(time.Now()) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Срок подачи предложений должен быть в будущем."))
+		return
+	}
+
+	// Закрытые торги раскрываются по сроку подачи, без него предложения скрыты навсегда
+	if newTenderRequest.Sealed && newTenderRequest.SubmissionDeadline == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Для закрытых торгов (sealed) обязателен submissionDeadline."))
+		return
+	}
+
+	// Создавать тендеры могут только редакторы и владельцы организации:
+	// несуществующий автор — 401, не ответственный за организацию — 403
+	var creatorID *uuid.UUID
+	if principal == nil {
+		creator, ok := authorizeUsername(w, newTenderRequest.CreatorUsername, organizationId.String(), models.PERMISSION_EDIT_TENDERS)
+		if !ok {
+			return
+		}
+		creatorID = &creator.ID
+	}
 	var tenders models.Tender
 
+	visibility := models.VISIBILITY_PUBLIC
+	if newTenderRequest.Visibility != "" {
+		if !newTenderRequest.Visibility.IsValid() {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Допустимые visibility: PUBLIC, INVITE_ONLY."))
+			return
+		}
+		visibility = newTenderRequest.Visibility
+	}
+
+	// Тендер с предквалификацией начинается с приёма квалификационных заявок
+	stage := models.STAGE_COMMERCIAL
+	if newTenderRequest.Prequalification {
+		stage = models.STAGE_PREQUALIFICATION
+	}
+
 	tender := models.Tender{
-		ID:             uuid.New(),
-		Name:           newTenderRequest.Name,
-		Description:    newTenderRequest.Description,
-		Status:         models.TENDER_CREATED,
-		ServiceType:    newTenderRequest.ServiceType,
-		OrganizationID: newTenderRequest.OrganizationID,
-		Version:        tenders.Version,
-		CreatedAt:      tenders.CreatedAt,
+		ID:                 uuid.New(),
+		Name:               newTenderRequest.Name,
+		Description:        newTenderRequest.Description,
+		Status:             models.TENDER_CREATED,
+		ServiceType:        newTenderRequest.ServiceType,
+		OrganizationID:     organizationId,
+		CreatorID:          creatorID,
+		UpdatedByID:        creatorID,
+		SubmissionDeadline: newTenderRequest.SubmissionDeadline,
+		Sealed:             newTenderRequest.Sealed,
+		Prequalification:   newTenderRequest.Prequalification,
+		Stage:              stage,
+		StageStatus:        models.STAGE_OPEN,
+		Visibility:         visibility,
+		Version:            tenders.Version,
+		CreatedAt:          tenders.CreatedAt,
+	}
+
+	if err := applyTenderRequirements(&tender, newTenderRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		return
 	}
 
-	if err := db.DB.Create(&tender).Error; err != nil {
+	if err := db.DB.Omit("Organization").Create(&tender).Error; err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(models.NewErrorResponse("Сервер не готов обрабатывать запросы."))
 		return
 	}
-	tenderResponse := models.Tender{
-		ID:          tender.ID,
-		Name:        newTenderRequest.Name,
-		Description: newTenderRequest.Description,
-		Status:      models.TENDER_CREATED,
-		ServiceType: newTenderRequest.ServiceType,
-		Version:     tender.Version,
-		CreatedAt:   time.Now(),
+
+	audit.Record(r.Context(), audit.ActionCreate, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), nil, tender)
+
+	response := models.Tender{
+		ID:                 tender.ID,
+		Name:               newTenderRequest.Name,
+		Description:        newTenderRequest.Description,
+		Status:             models.TENDER_CREATED,
+		ServiceType:        newTenderRequest.ServiceType,
+		CreatorID:          tender.CreatorID,
+		UpdatedByID:        tender.UpdatedByID,
+		SubmissionDeadline: tender.SubmissionDeadline,
+		Sealed:             tender.Sealed,
+		Prequalification:   tender.Prequalification,
+		Stage:              tender.Stage,
+		StageStatus:        tender.StageStatus,
+		Visibility:         tender.Visibility,
+		Budget:             tender.Budget,
+		BudgetCurrency:     tender.BudgetCurrency,
+		RequiredBy:         tender.RequiredBy,
+		Region:             tender.Region,
+		LineItems:          tender.LineItems,
+		Version:            tender.Version,
+		CreatedAt:          time.Now(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(tenderResponse)
+	json.NewEncoder(w).Encode(response)
 }
 
 func GetTendersHandler(w http.ResponseWriter, r *http.Request) {
 
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-	serviceTypeStr := r.URL.Query().Get("service_type")
-	limit := 10
-	offset := 0
+	filter, err := parseTenderFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
 
-	if limitStr != "" {
-		var err error
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil || limit <= 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			errorResponse := models.NewErrorResponse("Неверный формат параметра limit.")
-			json.NewEncoder(w).Encode(errorResponse)
-			return
-		}
+	sort, err := parseSort(r, tenderSortColumns, sortByName)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
-	if offsetStr != "" {
-		var err error
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			errorResponse := models.NewErrorResponse("Неверный формат параметра offset.")
-			json.NewEncoder(w).Encode(errorResponse)
-			return
-		}
+	pg, err := parsePage(r, sort)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Тендеры по приглашению видны только приглашённым и своей организации
+	employeeID, organizationIDs, err := tenderViewer(r)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
 	var tenders []models.Tender
-	query := db.DB.Model(&models.Tender{})
+	query := filter.apply(db.DB.Model(&models.Tender{})).
+		Where(visibleTendersCondition(employeeID, organizationIDs)).
+		Session(&gorm.Session{})
 
-	if serviceTypeStr != "" {
-		serviceTypeStr = strings.ToUpper(serviceTypeStr)
-		query = query.Where("service_type = ?", serviceTypeStr)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендеров.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
-	if err := query.Offset(offset).Limit(limit).Order("name ASC").Find(&tenders).Error; err != nil {
+	if err := pg.apply(query).Find(&tenders).Error; err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		errorResponse := models.NewErrorResponse("Ошибка при получении тендеров.")
 		json.NewEncoder(w).Encode(errorResponse)
@@ -144,18 +264,13 @@ func GetTendersHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	tenderResponses := make([]models.TenderResponse, len(tenders))
+	var last pageCursor
 	for i, tender := range tenders {
-		tenderResponses[i] = models.TenderResponse{
-			ID:          tender.ID.String(),
-			Name:        tender.Name,
-			Description: tender.Description,
-			Status:      tender.Status,
-			ServiceType: tender.ServiceType,
-			Version:     tender.Version,
-			CreatedAt:   tender.CreatedAt,
-		}
+		tenderResponses[i] = tenderResponse(tender)
+		last = pg.cursor(tenderSortValue(tender, sort.Key), tender.ID)
 	}
 
+	writePageHeaders(w, pg, total, len(tenders), last)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(tenderResponses)
@@ -163,42 +278,22 @@ func GetTendersHandler(w http.ResponseWriter, r *http.Request) {
 
 func GetUserTendersHandler(w http.ResponseWriter, r *http.Request) {
 
-	username := r.URL.Query().Get("username")
+	username := currentUsername(r)
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
 	log.Printf("Ищем пользователя с username: %s", username)
 
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 10
-	offset := 0
-
-	if limitStr != "" {
-		var err error
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil || limit <= 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			errorResponse := models.NewErrorResponse("Неверный формат параметра limit.")
-			json.NewEncoder(w).Encode(errorResponse)
-			return
-		}
-	}
-
-	if offsetStr != "" {
-		var err error
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			errorResponse := models.NewErrorResponse("Неверный формат параметра offset.")
-			json.NewEncoder(w).Encode(errorResponse)
-			return
-		}
+	pg, err := parsePage(r, sortByName)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
 	// 1. Найти пользователя по username
@@ -217,25 +312,54 @@ func GetUserTendersHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 2. Найти OrganizationID по UserID в OrganizationResponsible
-	var orgResponsible models.OrganizationResponsible
-	if err := db.DB.Where("user_id = ?", employee.ID).First(&orgResponsible).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			w.WriteHeader(http.StatusNotFound)
-			errorResponse := models.NewErrorResponse("Организация не найдена для данного пользователя.")
-			json.NewEncoder(w).Encode(errorResponse)
-			return
-		}
+	// 2. Найти все OrganizationID по UserID в OrganizationResponsible
+	organizationIDs, err := userOrganizationIDs(employee.ID)
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("Ошибка при получении организации: %v", err)
 		errorResponse := models.NewErrorResponse("Ошибка при получении организации.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
+	if len(organizationIDs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := models.NewErrorResponse("Организация не найдена для данного пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Необязательный фильтр по одной из организаций пользователя
+	if organizationIdStr := r.URL.Query().Get("organizationId"); organizationIdStr != "" {
+		organizationId, err := uuid.Parse(organizationIdStr)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := models.NewErrorResponse("Неверный формат параметра organizationId.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		if !containsString(organizationIDs, organizationId.String()) {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("Пользователь не является ответственным за организацию.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		organizationIDs = []string{organizationId.String()}
+	}
 
 	// 3. Найти все тендеры по OrganizationID
 	var tenders []models.Tender
-	if err := db.DB.Where("organization_id = ?", orgResponsible.OrganizationID).Offset(offset).Limit(limit).Order("name ASC").Find(&tenders).Error; err != nil {
+	query := db.DB.Model(&models.Tender{}).Where("organization_id IN ?", organizationIDs).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при подсчёте тендеров: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендеров.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if err := pg.apply(query).Find(&tenders).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			errorResponse := models.NewErrorResponse("Тендеры не найдены.")
@@ -250,18 +374,13 @@ func GetUserTendersHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	tenderResponses := make([]models.TenderResponse, len(tenders))
+	var last pageCursor
 	for i, tender := range tenders {
-		tenderResponses[i] = models.TenderResponse{
-			ID:          tender.ID.String(),
-			Name:        tender.Name,
-			Description: tender.Description,
-			Status:      tender.Status,
-			ServiceType: tender.ServiceType,
-			Version:     tender.Version,
-			CreatedAt:   tender.CreatedAt,
-		}
+		tenderResponses[i] = tenderResponse(tender)
+		last = pg.cursor(tender.Name, tender.ID)
 	}
 
+	writePageHeaders(w, pg, total, len(tenders), last)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(tenderResponses)
@@ -269,34 +388,10 @@ func GetUserTendersHandler(w http.ResponseWriter, r *http.Request) {
 
 func GetTenderStatusHandler(w http.ResponseWriter, r *http.Request) {
 
-	tenderIdStr := r.URL.Path[len("/api/tenders/") : len("/api/tenders/")+36]
-	tenderId, err := uuid.Parse(tenderIdStr)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Неверный формат идентификатора тендера.")
-		json.NewEncoder(w).Encode(errorResponse)
-		return
-	}
-
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
-		json.NewEncoder(w).Encode(errorResponse)
-		return
-	}
-
-	var tender models.Tender
-	if err := db.DB.First(&tender, "id = ?", tenderId).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			w.WriteHeader(http.StatusNotFound)
-			errorResponse := models.NewErrorResponse("Тендер не найден.")
-			json.NewEncoder(w).Encode(errorResponse)
-			return
-		}
-		w.WriteHeader(http.StatusInternalServerError)
-		errorResponse := models.NewErrorResponse("Ошибка при получении статуса тендера.")
-		json.NewEncoder(w).Encode(errorResponse)
+	// Статус виден тем же, кому виден тендер: чужой черновик или тендер
+	// без приглашения дают 404
+	tender, _, _, ok := loadVisibleTender(w, r)
+	if !ok {
 		return
 	}
 
@@ -325,11 +420,17 @@ func UpdateTenderStatusHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	status = strings.ToUpper(status)
+	if !models.TenderStatus(status).IsValid() {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Допустимые status: CREATED, PUBLISHED, CLOSED.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
 
-	username := r.URL.Query().Get("username")
+	username := currentUsername(r)
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
@@ -348,26 +449,45 @@ func UpdateTenderStatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tender.Status = models.TenderStatus(status)
+	employee, ok := authorizeUsername(w, username, tender.OrganizationID.String(), models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
+	}
 
-	if err := db.DB.Save(&tender).Error; err != nil {
+	// Закрытый тендер не открывается заново: по нему уже принято решение или истёк
+	// срок подачи. Статус проверяется под блокировкой, чтобы не затереть
+	// одновременное закрытие решением или планировщиком.
+	before := tender
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tender, "id = ?", tenderId).Error; err != nil {
+			return err
+		}
+		before = tender
+		if tender.Status == models.TENDER_CLOSED && models.TenderStatus(status) != models.TENDER_CLOSED {
+			return errTenderClosed
+		}
+		tender.Status = models.TenderStatus(status)
+		tender.UpdatedByID = &employee.ID
+		return tx.Omit("Organization").Save(&tender).Error
+	})
+	if err != nil {
+		if errors.Is(err, errTenderClosed) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		errorResponse := models.NewErrorResponse("Ошибка при обновлении статуса тендера.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
-	tenderResponse := models.TenderResponse{
-		ID:          tender.ID.String(),
-		Name:        tender.Name,
-		Description: tender.Description,
-		Status:      tender.Status,
-		ServiceType: tender.ServiceType,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt,
-	}
+
+	audit.Record(r.Context(), audit.ActionStatusChange, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), before, tender)
+
+	response := tenderResponse(tender)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(tenderResponse)
+	json.NewEncoder(w).Encode(response)
 }
 
 func EditTenderHandler(w http.ResponseWriter, r *http.Request) {
@@ -381,10 +501,10 @@ func EditTenderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
+	username := currentUsername(r)
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
@@ -399,11 +519,8 @@ func EditTenderHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Полученные данные: %s", string(body))
 
-	re := regexp.MustCompile(`[^\x00-\x7F]+`)
-	cleanBody := re.ReplaceAllString(string(body), "")
-
 	var updateData models.NewTenderRequest
-	if err := json.Unmarshal([]byte(cleanBody), &updateData); err != nil {
+	if err := json.Unmarshal(body, &updateData); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		log.Printf("Ошибка декодирования данных: %v", err)
 		errorResponse := models.NewErrorResponse("Данные неправильно сформированы или не соответствуют требованиям.")
@@ -425,55 +542,104 @@ func EditTenderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tenderVersion := models.TenderVersion{
-		TenderID:       tender.ID.String(),
-		Name:           tender.Name,
-		Description:    tender.Description,
-		Status:         tender.Status,
-		ServiceType:    tender.ServiceType,
-		OrganizationID: tender.OrganizationID,
-		Version:        tender.Version,
-		CreatedAt:      tender.CreatedAt,
+	employee, ok := authorizeUsername(w, username, tender.OrganizationID.String(), models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
 	}
 
-	if err := db.DB.Create(&tenderVersion).Error; err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		errorResponse := models.NewErrorResponse("Ошибка при сохранении версии тендера.")
+	// Срок подачи меняется, только если он передан в запросе
+	submissionDeadline := tender.SubmissionDeadline
+	if updateData.SubmissionDeadline != nil {
+		if !updateData.SubmissionDeadline.After(time.Now()) {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := models.NewErrorResponse("Срок подачи предложений должен быть в будущем.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		submissionDeadline = updateData.SubmissionDeadline
+	}
+
+	visibility := tender.Visibility
+	if updateData.Visibility != "" {
+		if !updateData.Visibility.IsValid() {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := models.NewErrorResponse("Допустимые visibility: PUBLIC, INVITE_ONLY.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		visibility = updateData.Visibility
+	}
+
+	if updateData.ServiceType != "" && !updateData.ServiceType.IsValid() {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Допустимые serviceType: CONSTRUCTION, DELIVERY, MANUFACTURE.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	newTender := models.Tender{
-		ID:             uuid.New(),
-		Name:           updateData.Name,
-		Description:    updateData.Description,
-		Status:         tender.Status,
-		ServiceType:    updateData.ServiceType,
-		OrganizationID: tender.OrganizationID,
-		Version:        tender.Version + 1,
-		CreatedAt:      time.Now(),
+	// Тендер редактируется на месте: id не меняется, чтобы приглашения, шорт-лист,
+	// критерии, вложения и предложения остались привязаны к нему.
+	// Меняются только переданные поля.
+	newTender := tender
+	if updateData.Name != "" {
+		newTender.Name = updateData.Name
+	}
+	if updateData.Description != "" {
+		newTender.Description = updateData.Description
+	}
+	if updateData.ServiceType != "" {
+		newTender.ServiceType = updateData.ServiceType
 	}
+	newTender.UpdatedByID = &employee.ID
+	newTender.SubmissionDeadline = submissionDeadline
+	newTender.Visibility = visibility
+	newTender.Version = tender.Version + 1
 
-	if err := db.DB.Create(&newTender).Error; err != nil {
+	// Сначала проверяем запрос целиком, версия пишется только для корректной правки
+	if err := applyTenderRequirements(&newTender, updateData); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Правка проверена по прочитанной версии: если тендер успели изменить,
+	// запись под блокировкой затёрла бы чужую правку
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.Tender
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", tender.ID).Error; err != nil {
+			return err
+		}
+		if current.Version != tender.Version {
+			return errTenderChanged
+		}
+		tenderVersion := current.Snapshot()
+		if err := tx.Omit("Tender").Create(&tenderVersion).Error; err != nil {
+			return err
+		}
+		newTender.Version = current.Version + 1
+		return tx.Omit("Organization").Save(&newTender).Error
+	})
+	if err != nil {
+		if errors.Is(err, errTenderChanged) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при сохранении новой версии тендера: %v", err)
 		errorResponse := models.NewErrorResponse("Ошибка при создании новой версии тендера.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	tenderResponse := models.TenderResponse{
-		ID:          newTender.ID.String(),
-		Name:        newTender.Name,
-		Description: newTender.Description,
-		Status:      newTender.Status,
-		ServiceType: newTender.ServiceType,
-		Version:     newTender.Version,
-		CreatedAt:   newTender.CreatedAt,
-	}
+	audit.Record(r.Context(), audit.ActionEdit, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), tender, newTender)
+
+	response := tenderResponse(newTender)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(tenderResponse)
+	json.NewEncoder(w).Encode(response)
 }
 
 func RollbackTenderHandler(w http.ResponseWriter, r *http.Request) {
@@ -496,10 +662,10 @@ func RollbackTenderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
+	username := currentUsername(r)
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
@@ -518,37 +684,67 @@ func RollbackTenderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newTender := models.Tender{
-		ID:             uuid.New(),
-		Name:           previousTender.Name,
-		Description:    previousTender.Description,
-		Status:         previousTender.Status,
-		ServiceType:    previousTender.ServiceType,
-		OrganizationID: previousTender.OrganizationID,
-		Version:        previousTender.Version,
-		CreatedAt:      previousTender.CreatedAt,
+	employee, ok := authorizeUsername(w, username, previousTender.OrganizationID.String(), models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
 	}
 
-	if err := db.DB.Create(&newTender).Error; err != nil {
+	// Откат — тоже новая версия того же тендера: содержимое берётся из
+	// выбранной версии, а текущее состояние, перечитанное под блокировкой,
+	// уходит в историю
+	var currentTender, newTender models.Tender
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&currentTender, "id = ?", tenderId).Error; err != nil {
+			return err
+		}
+		if currentTender.Status == models.TENDER_CLOSED {
+			return errTenderClosed
+		}
+		tenderVersion := currentTender.Snapshot()
+		if err := tx.Omit("Tender").Create(&tenderVersion).Error; err != nil {
+			return err
+		}
+
+		newTender = currentTender
+		newTender.Name = previousTender.Name
+		newTender.Description = previousTender.Description
+		newTender.Status = previousTender.Status
+		newTender.ServiceType = previousTender.ServiceType
+		newTender.UpdatedByID = &employee.ID
+		newTender.SubmissionDeadline = previousTender.SubmissionDeadline
+		newTender.Sealed = previousTender.Sealed
+		newTender.Prequalification = previousTender.Prequalification
+		newTender.Stage = previousTender.Stage
+		newTender.StageStatus = previousTender.StageStatus
+		newTender.Visibility = previousTender.Visibility
+		newTender.Budget = previousTender.Budget
+		newTender.BudgetCurrency = previousTender.BudgetCurrency
+		newTender.RequiredBy = previousTender.RequiredBy
+		newTender.Region = previousTender.Region
+		newTender.LineItems = previousTender.LineItems
+		newTender.Version = currentTender.Version + 1
+		return tx.Omit("Organization").Save(&newTender).Error
+	})
+	if err != nil {
+		if errors.Is(err, errTenderClosed) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при откате тендера: %v", err)
 		errorResponse := models.NewErrorResponse("Ошибка при создании новой версии тендера.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	tenderResponse := models.TenderResponse{
-		ID:          newTender.ID.String(),
-		Name:        newTender.Name,
-		Description: newTender.Description,
-		Status:      newTender.Status,
-		ServiceType: newTender.ServiceType,
-		Version:     newTender.Version,
-		CreatedAt:   newTender.CreatedAt,
-	}
+	audit.Record(r.Context(), audit.ActionRollback, models.ENTITY_TENDER, tenderId.String(), newTender.OrganizationID.String(), currentTender, newTender)
+
+	response := tenderResponse(newTender)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(tenderResponse)
+	json.NewEncoder(w).Encode(response)
 }
 
 func CreateBidHandler(w http.ResponseWriter, r *http.Request) {
@@ -565,10 +761,7 @@ func CreateBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	re := regexp.MustCompile(`[^\x00-\x7F]+`)
-	cleanBody := re.ReplaceAllString(string(body), "")
-
-	if err := json.Unmarshal([]byte(cleanBody), &newBidRequest); err != nil {
+	if err := json.Unmarshal(body, &newBidRequest); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
 		return
@@ -579,9 +772,35 @@ func CreateBidHandler(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(models.NewErrorResponse("Поля name, description, tenderId, authorType и authorId обязательны."))
 		return
 	}
+	tenderId, err := uuid.Parse(newBidRequest.TenderID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Неверный формат идентификатора тендера."))
+		return
+	}
+
+	authorId, err := uuid.Parse(newBidRequest.AuthorID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Неверный формат идентификатора автора."))
+		return
+	}
+
+	if newBidRequest.AuthorType != models.AUTHOR_USER && newBidRequest.AuthorType != models.AUTHOR_ORGANIZATION {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Допустимые authorType: USER, ORGANIZATION."))
+		return
+	}
+
+	// Подать предложение можно только от своего имени или от организации, за которую отвечаешь
+	author, ok := authorizeBidAuthor(w, r, models.Bid{AuthorType: newBidRequest.AuthorType, AuthorID: authorId})
+	if !ok {
+		return
+	}
+
 	var tender models.Tender
 	var bid models.Bid
-	if err := db.DB.First(&tender, "id = ?", newBidRequest.TenderID).Error; err != nil {
+	if err := db.DB.First(&tender, "id = ?", tenderId).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			errorResponse := models.NewErrorResponse("Тендер не найден.")
@@ -594,33 +813,95 @@ func CreateBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Черновики не видны поставщикам, а по закрытым тендерам приём уже завершён
+	if tender.Status != models.TENDER_PUBLISHED {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Предложения принимаются только по опубликованным тендерам."))
+		return
+	}
+
+	if deadlinePassed(tender) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Срок подачи предложений по тендеру истёк."))
+		return
+	}
+
+	if tender.StageStatus != models.STAGE_OPEN {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Приём заявок на текущем этапе тендера завершён."))
+		return
+	}
+
+	// На коммерческий этап тендера с предквалификацией допускаются только авторы из шорт-листа
+	if tender.Prequalification && tender.Stage == models.STAGE_COMMERCIAL {
+		var shortlisted int64
+		if err := db.DB.Model(&models.ShortlistEntry{}).Where("tender_id = ? AND author_id = ?", tender.ID, authorId).Count(&shortlisted).Error; err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка при проверке шорт-листа."))
+			return
+		}
+		if shortlisted == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Автор не прошёл предквалификацию и не допущен к коммерческому этапу."))
+			return
+		}
+	}
+
+	if tender.Visibility == models.VISIBILITY_INVITE_ONLY {
+		invited, err := invitedAuthor(tender.ID, newBidRequest.AuthorType, authorId)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка при проверке приглашений."))
+			return
+		}
+		if !invited {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(models.NewErrorResponse("Тендер доступен только по приглашению."))
+			return
+		}
+	}
+
 	newbid := models.Bid{
 		ID:          uuid.New(),
 		Name:        newBidRequest.Name,
 		Description: newBidRequest.Description,
 		Status:      models.BID_CREATED,
-		TenderID:    newBidRequest.TenderID,
+		TenderID:    tenderId,
 		AuthorType:  newBidRequest.AuthorType,
-		AuthorID:    newBidRequest.AuthorID,
+		AuthorID:    authorId,
+		Stage:       tender.Stage,
+		UpdatedByID: &author.ID,
 		Version:     bid.Version,
 		CreatedAt:   time.Now(),
 	}
 
-	if err := db.DB.Create(&newbid).Error; err != nil {
+	if err := applyBidTerms(&newbid, newBidRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		return
+	}
+
+	if err := applyBidLineItems(&newbid, newBidRequest, tender); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		return
+	}
+
+	if err := checkBidStage(newbid); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+		return
+	}
+
+	if err := db.DB.Omit("Tender").Create(&newbid).Error; err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(models.NewErrorResponse("Сервер не готов обрабатывать запросы."))
 		return
 	}
 
-	bidRespone := models.Bid{
-		ID:         bid.ID,
-		Name:       newBidRequest.Name,
-		Status:     models.BID_CREATED,
-		AuthorType: newBidRequest.AuthorType,
-		AuthorID:   newBidRequest.AuthorID,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt,
-	}
+	audit.Record(r.Context(), audit.ActionCreate, models.ENTITY_BID, newbid.ID.String(), tender.OrganizationID.String(), nil, newbid)
+
+	bidRespone := bidResponseWithBudget(newbid)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -629,41 +910,22 @@ func CreateBidHandler(w http.ResponseWriter, r *http.Request) {
 
 func GetUserBidsHandler(w http.ResponseWriter, r *http.Request) {
 	// Получаем параметры запроса
-	username := r.URL.Query().Get("username")
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	username := currentUsername(r)
 
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	// Парсим limit и offset
-	limit := 10 // Значение по умолчанию
-	offset := 0
-
-	if limitStr != "" {
-		var err error
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil || limit < 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			errorResponse := models.NewErrorResponse("Некорректное значение параметра limit.")
-			json.NewEncoder(w).Encode(errorResponse)
-			return
-		}
-	}
-
-	if offsetStr != "" {
-		var err error
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			errorResponse := models.NewErrorResponse("Некорректное значение параметра offset.")
-			json.NewEncoder(w).Encode(errorResponse)
-			return
-		}
+	// Парсим limit, offset и after
+	pg, err := parsePage(r, sortByName)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
 	log.Printf("Ищем заявки для пользователя с username: %s", username)
@@ -685,10 +947,17 @@ func GetUserBidsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Шаг 2: Получаем все bids по AuthorID (который равен UserID)
 	var bids []models.Bid
-	if err := db.DB.Where("author_id = ?", employee.ID).
-		Limit(limit).
-		Offset(offset).
-		Find(&bids).Error; err != nil {
+	query := db.DB.Model(&models.Bid{}).Where("author_id = ?", employee.ID).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении заявок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if err := pg.apply(query).Find(&bids).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			errorResponse := models.NewErrorResponse("Заявки не найдены.")
@@ -703,17 +972,14 @@ func GetUserBidsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Формируем ответ
 	bidResponses := make([]models.BidResponse, len(bids))
+	var last pageCursor
 	for i, bid := range bids {
-		bidResponses[i] = models.BidResponse{
-			ID:         bid.ID.String(),
-			Name:       bid.Name,
-			Status:     bid.Status,
-			AuthorType: bid.AuthorType,
-			AuthorID:   bid.AuthorID,
-			Version:    bid.Version,
-			CreatedAt:  bid.CreatedAt,
-		}
+		bidResponses[i] = bidResponse(bid)
+		last = pg.cursor(bid.Name, bid.ID)
 	}
+	flagBudgets(bidResponses, bids)
+
+	writePageHeaders(w, pg, total, len(bids), last)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -731,66 +997,107 @@ func GetBidsForTenderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+	principal := auth.OrganizationFrom(r.Context())
+	username := currentUsername(r)
+	if username == "" && principal == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	sort, err := parseSort(r, bidSortColumns, sortByName)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	sort = bidSortOrder(sort)
 
-	limit := 10
-	offset := 0
+	pg, err := parsePage(r, sort)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
 
-	if limitStr != "" {
-		var err error
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil || limit <= 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			errorResponse := models.NewErrorResponse("Неверный формат параметра limit.")
+	var tender models.Tender
+	if err := db.DB.First(&tender, "id = ?", tenderId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Тендер не найден.")
 			json.NewEncoder(w).Encode(errorResponse)
 			return
 		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
-	if offsetStr != "" {
-		var err error
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			errorResponse := models.NewErrorResponse("Неверный формат параметра offset.")
+	var bids []models.Bid
+	var query *gorm.DB
+
+	if principal != nil {
+		// Интеграция организации видит все заявки на свои тендеры
+		if !principal.HasScope(models.SCOPE_BIDS_READ) {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("API-ключу не выдан scope bids:read.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		if tender.OrganizationID.String() != principal.OrganizationID {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("Недостаточно прав для выполнения действия.")
 			json.NewEncoder(w).Encode(errorResponse)
 			return
 		}
-	}
-
-	log.Printf("Ищем заявки для тендера с ID: %s и пользователя с username: %s", tenderId.String(), username)
 
-	// Шаг 1: Получаем UserID из таблицы Employee по username
-	var employee models.Employee
-	if err := db.DB.Where("username = ?", username).First(&employee).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			w.WriteHeader(http.StatusNotFound)
-			errorResponse := models.NewErrorResponse("Пользователь не найден.")
+		query = db.DB.Model(&models.Bid{}).Where("tender_id = ?", tenderId.String()).Session(&gorm.Session{})
+	} else {
+		log.Printf("Ищем заявки для тендера с ID: %s и пользователя с username: %s", tenderId.String(), username)
+
+		// Шаг 1: Получаем UserID из таблицы Employee по username
+		var employee models.Employee
+		if err := db.DB.Where("username = ?", username).First(&employee).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				w.WriteHeader(http.StatusNotFound)
+				errorResponse := models.NewErrorResponse("Пользователь не найден.")
+				json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
 			json.NewEncoder(w).Encode(errorResponse)
 			return
 		}
+
+		// Шаг 2: Получаем все bids по AuthorID (который равен UserID) и TenderID
+		query = db.DB.Model(&models.Bid{}).Where("author_id = ? AND tender_id = ?", employee.ID, tenderId).Session(&gorm.Session{})
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		errorResponse := models.NewErrorResponse("Ошибка при получении заявок.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	// Шаг 2: Получаем все bids по AuthorID (который равен UserID) и TenderID
-	var bids []models.Bid
-	if err := db.DB.Where("author_id = ? AND tender_id = ?", employee.ID, tenderId).
-		Limit(limit).
-		Offset(offset).
-		Order("name ASC").
-		Find(&bids).Error; err != nil {
+	// При закрытых торгах до срока подачи список одинаков для любого способа входа:
+	// только число предложений, без содержимого
+	if bidsSealed(tender) {
+		w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+		w.Header().Set("X-Sealed-Until", tender.SubmissionDeadline.Format(time.RFC3339))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode([]models.BidResponse{})
+		return
+	}
+
+	if err := pg.apply(query).Find(&bids).Error; err != nil {
 
 		if err == gorm.ErrRecordNotFound {
 			w.WriteHeader(http.StatusNotFound)
@@ -807,17 +1114,14 @@ func GetBidsForTenderHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Формируем ответ
 	bidResponses := make([]models.BidResponse, len(bids))
+	var last pageCursor
 	for i, bid := range bids {
-		bidResponses[i] = models.BidResponse{
-			ID:         bid.ID.String(),
-			Name:       bid.Name,
-			Status:     bid.Status,
-			AuthorType: bid.AuthorType,
-			AuthorID:   bid.AuthorID,
-			Version:    bid.Version,
-			CreatedAt:  bid.CreatedAt,
-		}
+		bidResponses[i] = bidResponse(bid)
+		last = pg.cursor(bidSortValue(bid, pg.Sort), bid.ID)
 	}
+	flagBudgets(bidResponses, bids)
+
+	writePageHeaders(w, pg, total, len(bids), last)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -835,10 +1139,10 @@ func GetBidStatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
+	username := currentUsername(r)
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
@@ -882,11 +1186,17 @@ func UpdateBidStatusHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	status = strings.ToUpper(status)
+	if !models.BidStatus(status).IsValid() {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Допустимые status: CREATED, PUBLISHED, CANCELED.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
 
-	username := r.URL.Query().Get("username")
+	username := currentUsername(r)
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
@@ -905,23 +1215,30 @@ func UpdateBidStatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Публикует и отменяет предложение только его автор
+	author, ok := authorizeBidAuthor(w, r, bid)
+	if !ok {
+		return
+	}
+
+	if bidsFrozen(w, bid.TenderID) {
+		return
+	}
+
+	before := bid
 	bid.Status = models.BidStatus(status)
+	bid.UpdatedByID = &author.ID
 
-	if err := db.DB.Save(&bid).Error; err != nil {
+	if err := db.DB.Omit("Tender").Save(&bid).Error; err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		errorResponse := models.NewErrorResponse("Ошибка при обновлении статуса предложения.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
-	bidResponses := models.BidResponse{
-		ID:         bid.ID.String(),
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorID:   bid.AuthorID,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt,
-	}
+
+	audit.Record(r.Context(), audit.ActionStatusChange, models.ENTITY_BID, bid.ID.String(), tenderOrganizationID(bid.TenderID), before, bid)
+
+	bidResponses := bidResponseWithBudget(bid)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -939,10 +1256,10 @@ func EditBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
+	username := currentUsername(r)
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
@@ -957,11 +1274,8 @@ func EditBidHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Полученные данные: %s", string(body))
 
-	re := regexp.MustCompile(`[^\x00-\x7F]+`)
-	cleanBody := re.ReplaceAllString(string(body), "")
-
 	var updateData models.NewBidRequest
-	if err := json.Unmarshal([]byte(cleanBody), &updateData); err != nil {
+	if err := json.Unmarshal(body, &updateData); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		log.Printf("Ошибка декодирования данных: %v", err)
 		errorResponse := models.NewErrorResponse("Данные неправильно сформированы или не соответствуют требованиям.")
@@ -983,53 +1297,86 @@ func EditBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bidVersion := models.BidVersion{
-		BidID:       bid.ID.String(),
-		Name:        bid.Name,
-		Description: bid.Description,
-		Status:      bid.Status,
-		TenderID:    bid.TenderID,
-		AuthorType:  bid.AuthorType,
-		AuthorID:    bid.AuthorID,
-		Version:     bid.Version,
-		CreatedAt:   bid.CreatedAt,
+	author, ok := authorizeBidAuthor(w, r, bid)
+	if !ok {
+		return
+	}
+
+	if bidsFrozen(w, bid.TenderID) {
+		return
+	}
+
+	// Предложение редактируется на месте: id не меняется, чтобы оценки,
+	// ставки аукциона и вложения остались привязаны к нему
+	newBid := bid
+	newBid.Name = updateData.Name
+	newBid.Description = updateData.Description
+	newBid.UpdatedByID = &author.ID
+	newBid.Version = bid.Version + 1
+
+	// Сначала проверяем запрос целиком, версия пишется только для корректной правки
+	if err := applyBidTerms(&newBid, updateData); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
-	if err := db.DB.Create(&bidVersion).Error; err != nil {
+	var tender models.Tender
+	if err := db.DB.Select("id", "line_items").First(&tender, "id = ?", bid.TenderID).Error; err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		errorResponse := models.NewErrorResponse("Ошибка при сохранении версии предложения.")
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	newBid := models.Bid{
-		ID:          uuid.New(),
-		Name:        updateData.Name,
-		Description: updateData.Description,
-		Status:      bid.Status,
-		TenderID:    bid.TenderID,
-		AuthorType:  bid.AuthorType,
-		AuthorID:    bid.AuthorID,
-		Version:     bid.Version + 1,
-		CreatedAt:   time.Now(),
+	if err := applyBidLineItems(&newBid, updateData, tender); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if err := checkBidStage(newBid); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
-	if err := db.DB.Create(&newBid).Error; err != nil {
+	// Правка проверена по прочитанной версии: если предложение успели изменить,
+	// запись под блокировкой затёрла бы чужую правку
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.Bid
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", bid.ID).Error; err != nil {
+			return err
+		}
+		if current.Version != bid.Version {
+			return errBidChanged
+		}
+		bidVersion := current.Snapshot()
+		if err := tx.Omit("Bid").Create(&bidVersion).Error; err != nil {
+			return err
+		}
+		newBid.Version = current.Version + 1
+		return tx.Omit("Tender").Save(&newBid).Error
+	})
+	if err != nil {
+		if errors.Is(err, errBidChanged) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при сохранении новой версии предложения: %v", err)
 		errorResponse := models.NewErrorResponse("Ошибка при создании новой версии предложения.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	bidResponses := models.BidResponse{
-		ID:         newBid.ID.String(),
-		Name:       newBid.Name,
-		Status:     newBid.Status,
-		AuthorType: newBid.AuthorType,
-		AuthorID:   newBid.AuthorID,
-		Version:    newBid.Version,
-		CreatedAt:  newBid.CreatedAt,
-	}
+	audit.Record(r.Context(), audit.ActionEdit, models.ENTITY_BID, bid.ID.String(), tenderOrganizationID(bid.TenderID), bid, newBid)
+
+	bidResponses := bidResponseWithBudget(newBid)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -1056,10 +1403,10 @@ func RollbackBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	username := r.URL.Query().Get("username")
+	username := currentUsername(r)
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := models.NewErrorResponse("Параметр username обязателен.")
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
@@ -1078,36 +1425,119 @@ func RollbackBidHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newBid := models.Bid{
-		ID:          uuid.New(),
-		Name:        previousBid.Name,
-		Description: previousBid.Description,
-		Status:      previousBid.Status,
-		TenderID:    previousBid.TenderID,
-		AuthorType:  previousBid.AuthorType,
-		AuthorID:    previousBid.AuthorID,
-		Version:     previousBid.Version,
-		CreatedAt:   previousBid.CreatedAt,
+	var currentBid models.Bid
+	if err := db.DB.First(&currentBid, "id = ?", bidId).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
-	if err := db.DB.Create(&newBid).Error; err != nil {
+	author, ok := authorizeBidAuthor(w, r, currentBid)
+	if !ok {
+		return
+	}
+
+	if bidsFrozen(w, previousBid.TenderID) {
+		return
+	}
+
+	// Откат — тоже новая версия того же предложения: содержимое берётся из
+	// выбранной версии, а текущее состояние, перечитанное под блокировкой,
+	// уходит в историю
+	var newBid models.Bid
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&currentBid, "id = ?", bidId).Error; err != nil {
+			return err
+		}
+		bidVersion := currentBid.Snapshot()
+		if err := tx.Omit("Bid").Create(&bidVersion).Error; err != nil {
+			return err
+		}
+
+		newBid = currentBid
+		newBid.Name = previousBid.Name
+		newBid.Description = previousBid.Description
+		newBid.Status = previousBid.Status
+		newBid.Amount = previousBid.Amount
+		newBid.Currency = previousBid.Currency
+		newBid.DeliveryDate = previousBid.DeliveryDate
+		newBid.WarrantyMonths = previousBid.WarrantyMonths
+		newBid.PaymentTerms = previousBid.PaymentTerms
+		newBid.Stage = previousBid.Stage
+		newBid.LineItems = previousBid.LineItems
+		newBid.UpdatedByID = &author.ID
+		newBid.Version = currentBid.Version + 1
+		return tx.Omit("Tender").Save(&newBid).Error
+	})
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		errorResponse := models.NewErrorResponse("Ошибка при создании новой версии тендера.")
+		log.Printf("Ошибка при откате предложения: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при создании новой версии предложения.")
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	bidResponses := models.BidResponse{
-		ID:         newBid.ID.String(),
-		Name:       newBid.Name,
-		Status:     newBid.Status,
-		AuthorType: newBid.AuthorType,
-		AuthorID:   newBid.AuthorID,
-		Version:    newBid.Version,
-		CreatedAt:  newBid.CreatedAt,
-	}
+	audit.Record(r.Context(), audit.ActionRollback, models.ENTITY_BID, bidId.String(), tenderOrganizationID(newBid.TenderID), currentBid, newBid)
+
+	bidResponses := bidResponseWithBudget(newBid)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(bidResponses)
 }
+
+// deadlinePassed сообщает, истёк ли срок подачи предложений по тендеру.
+func deadlinePassed(tender models.Tender) bool {
+	return tender.SubmissionDeadline != nil && !time.Now().Before(*tender.SubmissionDeadline)
+}
+
+// sealedTendersCondition отбирает тендеры, предложения по которым ещё скрыты;
+// параметр — текущее время.
+const sealedTendersCondition = "sealed AND submission_deadline > ?"
+
+// bidsSealed сообщает, скрыто ли содержимое предложений от организации тендера.
+// При закрытых торгах предложения раскрываются все сразу по истечении срока подачи.
+func bidsSealed(tender models.Tender) bool {
+	return tender.Sealed && !deadlinePassed(tender)
+}
+
+// bidsFrozen проверяет, закрыт ли тендер, истёк ли срок подачи или идёт ли
+// аукцион. Такие предложения заморожены: при попытке их изменить пишет 400
+// и возвращает true.
+func bidsFrozen(w http.ResponseWriter, tenderID uuid.UUID) bool {
+	var tender models.Tender
+	if err := db.DB.Select("status", "submission_deadline").First(&tender, "id = ?", tenderID).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return true
+	}
+	if tender.Status == models.TENDER_CLOSED {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Тендер закрыт, предложения по нему больше нельзя изменять.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return true
+	}
+	if deadlinePassed(tender) {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Срок подачи предложений по тендеру истёк.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return true
+	}
+
+	var auctions int64
+	if err := db.DB.Model(&models.Auction{}).Where("tender_id = ? AND status = ?", tenderID, models.AUCTION_ACTIVE).Count(&auctions).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при проверке аукциона.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return true
+	}
+	if auctions > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("По тендеру идёт аукцион, цена меняется только ставками.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return true
+	}
+	return false
+}
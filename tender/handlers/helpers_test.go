@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+var setupDB sync.Once
+
+// requireDB подключает тесты к базе из переменных POSTGRES_*. Без POSTGRES_HOST
+// тест пропускается: проверки прав и гонок имеют смысл только на настоящем Postgres.
+func requireDB(t *testing.T) {
+	t.Helper()
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST не задан, тест с базой пропущен")
+	}
+	setupDB.Do(func() {
+		db.Connect()
+		db.Migrate()
+	})
+}
+
+// testRouter повторяет маршруты из cmd/main.go, которые проверяются в тестах.
+func testRouter() *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/tenders/new", CreateTenderHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/status", GetTenderStatusHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/status", UpdateTenderStatusHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/tenders/{tenderId}/edit", EditTenderHandler).Methods(http.MethodPatch)
+	router.HandleFunc("/api/tenders/{tenderId}/rollback/{version}", RollbackTenderHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/tenders/{tenderId}/bids/compare", GetBidComparisonHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/tenders/{tenderId}/criteria", SetEvaluationCriteriaHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/tenders/{tenderId}/auction/start", StartAuctionHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/auction/finish", FinishAuctionHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/shortlist", AddToShortlistHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/invitations", CreateInvitationHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/questions", AskQuestionHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/tenders/{tenderId}/questions/{questionId}/answer", AnswerQuestionHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/bids/{tenderId}/list", GetBidsForTenderHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/bids/{bidId}/status", UpdateBidStatusHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/bids/{bidId}/edit", EditBidHandler).Methods(http.MethodPatch)
+	router.HandleFunc("/api/bids/{bidId}/rollback/{version}", RollbackBidHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/bids/{bidId}/submit_decision", SubmitBidDecisionHandler).Methods(http.MethodPut)
+	router.HandleFunc("/api/bids/{bidId}/scores", SubmitBidScoresHandler).Methods(http.MethodPut)
+	return router
+}
+
+// principal — кто выполняет запрос: сотрудник, API-ключ организации или никто.
+type principal struct {
+	employee     *models.Employee
+	organization *auth.OrganizationPrincipal
+}
+
+func asEmployee(employee models.Employee) principal {
+	return principal{employee: &employee}
+}
+
+func serve(t *testing.T, method, target string, body interface{}, as principal) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+
+	r := httptest.NewRequest(method, target, &payload)
+	ctx := r.Context()
+	if as.employee != nil {
+		ctx = auth.WithEmployee(ctx, as.employee)
+	}
+	if as.organization != nil {
+		ctx = auth.WithOrganization(ctx, as.organization)
+	}
+
+	w := httptest.NewRecorder()
+	testRouter().ServeHTTP(w, r.WithContext(ctx))
+	return w
+}
+
+func createEmployee(t *testing.T) models.Employee {
+	t.Helper()
+	employee := models.Employee{ID: uuid.New(), Username: "test-" + uuid.NewString()}
+	if err := db.DB.Create(&employee).Error; err != nil {
+		t.Fatalf("create employee: %v", err)
+	}
+	return employee
+}
+
+// createOrganization создаёт организацию и назначает сотрудникам роли в ней.
+func createOrganization(t *testing.T, members map[*models.Employee]models.OrganizationRole) models.Organization {
+	t.Helper()
+	organization := models.Organization{ID: uuid.New(), Name: "test", Type: models.LLC}
+	if err := db.DB.Create(&organization).Error; err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	for employee, role := range members {
+		responsible := models.OrganizationResponsible{ID: uuid.New(), OrganizationID: organization.ID, UserID: employee.ID, Role: role}
+		if err := db.DB.Omit("Organization", "User").Create(&responsible).Error; err != nil {
+			t.Fatalf("create responsible: %v", err)
+		}
+	}
+	return organization
+}
+
+func createTender(t *testing.T, organization models.Organization, creator models.Employee, status models.TenderStatus, change func(*models.Tender)) models.Tender {
+	t.Helper()
+	tender := models.Tender{
+		ID:             uuid.New(),
+		Name:           "Тендер",
+		Description:    "Описание",
+		Status:         status,
+		ServiceType:    models.CONSTRUCTION,
+		OrganizationID: organization.ID,
+		CreatorID:      &creator.ID,
+		UpdatedByID:    &creator.ID,
+		Stage:          models.STAGE_COMMERCIAL,
+		StageStatus:    models.STAGE_OPEN,
+		Visibility:     models.VISIBILITY_PUBLIC,
+		Version:        1,
+		CreatedAt:      time.Now(),
+	}
+	if change != nil {
+		change(&tender)
+	}
+	if err := db.DB.Omit("Organization").Create(&tender).Error; err != nil {
+		t.Fatalf("create tender: %v", err)
+	}
+	return tender
+}
+
+func createBid(t *testing.T, tender models.Tender, author models.Employee, status models.BidStatus, change func(*models.Bid)) models.Bid {
+	t.Helper()
+	bid := models.Bid{
+		ID:          uuid.New(),
+		Name:        "Предложение",
+		Description: "Описание",
+		Status:      status,
+		TenderID:    tender.ID,
+		AuthorType:  models.AUTHOR_USER,
+		AuthorID:    author.ID,
+		Stage:       models.STAGE_COMMERCIAL,
+		UpdatedByID: &author.ID,
+		Version:     1,
+		CreatedAt:   time.Now(),
+	}
+	if change != nil {
+		change(&bid)
+	}
+	if err := db.DB.Omit("Tender").Create(&bid).Error; err != nil {
+		t.Fatalf("create bid: %v", err)
+	}
+	return bid
+}
+
+// createAPIKey заводит ключ организации и возвращает principal, как его собирает Authenticate.
+func createAPIKey(t *testing.T, organization models.Organization, scopes ...models.APIKeyScope) principal {
+	t.Helper()
+	key := models.OrganizationAPIKey{
+		ID:             uuid.New(),
+		OrganizationID: organization.ID,
+		Name:           "test",
+		Prefix:         "tsk_test",
+		KeyHash:        uuid.NewString(),
+		Scopes:         auth.JoinScopes(scopes),
+		CreatedAt:      time.Now(),
+	}
+	if err := db.DB.Omit("Organization").Create(&key).Error; err != nil {
+		t.Fatalf("create api key: %v", err)
+	}
+	return principal{organization: &auth.OrganizationPrincipal{
+		OrganizationID: organization.ID.String(),
+		KeyID:          key.ID.String(),
+		Scopes:         scopes,
+	}}
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"tender/audit"
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tenderViewer возвращает сотрудника, от имени которого идёт запрос, и его
+// организации. Для API-ключа — только организацию ключа, для анонимного
+// запроса — nil и пустой список.
+func tenderViewer(r *http.Request) (*uuid.UUID, []string, error) {
+	if principal := auth.OrganizationFrom(r.Context()); principal != nil {
+		return nil, []string{principal.OrganizationID}, nil
+	}
+
+	username := currentUsername(r)
+	if username == "" {
+		return nil, nil, nil
+	}
+
+	var employee models.Employee
+	if err := db.DB.Select("id").Where("username = ?", username).First(&employee).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	organizationIDs, err := userOrganizationIDs(employee.ID)
+	return &employee.ID, organizationIDs, err
+}
+
+// visibleTendersCondition отбирает тендеры, которые видит зритель: публичные,
+// своих организаций и те, куда приглашён он сам или его организация.
+func visibleTendersCondition(employeeID *uuid.UUID, organizationIDs []string) *gorm.DB {
+	condition := db.DB.Where("visibility = ?", models.VISIBILITY_PUBLIC)
+	if len(organizationIDs) > 0 {
+		condition = condition.Or("organization_id IN ?", organizationIDs).
+			Or("id IN (SELECT tender_id FROM tender_invitations WHERE invitee_type = ? AND invitee_id IN ?)", models.AUTHOR_ORGANIZATION, organizationIDs)
+	}
+	if employeeID != nil {
+		condition = condition.Or("id IN (SELECT tender_id FROM tender_invitations WHERE invitee_type = ? AND invitee_id = ?)", models.AUTHOR_USER, *employeeID)
+	}
+	return condition
+}
+
+// invitedAuthor сообщает, приглашён ли автор предложения в тендер. Сотрудник
+// считается приглашённым и через организацию, за которую он отвечает.
+func invitedAuthor(tenderID uuid.UUID, authorType models.BidAuthorType, authorID uuid.UUID) (bool, error) {
+	query := db.DB.Model(&models.TenderInvitation{}).Where("tender_id = ?", tenderID)
+	if authorType == models.AUTHOR_USER {
+		query = query.Where("(invitee_type = ? AND invitee_id = ?) OR (invitee_type = ? AND invitee_id IN (SELECT organization_id FROM organization_responsibles WHERE user_id = ?))",
+			models.AUTHOR_USER, authorID, models.AUTHOR_ORGANIZATION, authorID)
+	} else {
+		query = query.Where("invitee_type = ? AND invitee_id = ?", authorType, authorID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func GetInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, _, ok := authorizeTender(w, r, models.PERMISSION_READ)
+	if !ok {
+		return
+	}
+
+	var invitations []models.TenderInvitation
+	if err := db.DB.Where("tender_id = ?", tender.ID).Order("created_at ASC").Find(&invitations).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении приглашений.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invitations)
+}
+
+// CreateInvitationHandler приглашает в тендер организацию или сотрудника.
+// Приглашения можно готовить заранее, действуют они при видимости INVITE_ONLY.
+func CreateInvitationHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, employee, ok := authorizeTender(w, r, models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
+	}
+
+	var request models.InvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	inviteeId, err := uuid.Parse(request.InviteeID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Неверный формат идентификатора приглашённого."))
+		return
+	}
+
+	var invitee interface{}
+	switch request.InviteeType {
+	case models.AUTHOR_ORGANIZATION:
+		invitee = &models.Organization{}
+	case models.AUTHOR_USER:
+		invitee = &models.Employee{}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Допустимые inviteeType: ORGANIZATION, USER."))
+		return
+	}
+
+	if err := db.DB.First(invitee, "id = ?", inviteeId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Приглашённая организация или сотрудник не найдены.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при проверке приглашённого.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	invitation := models.TenderInvitation{
+		ID:          uuid.New(),
+		TenderID:    tender.ID,
+		InviteeType: request.InviteeType,
+		InviteeID:   inviteeId,
+		InvitedByID: employee.ID,
+		CreatedAt:   time.Now(),
+	}
+	if err := db.DB.Omit("Tender").Create(&invitation).Error; err != nil {
+		// Повтор, в том числе одновременный, отсекает уникальный индекс по тендеру и приглашённому
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			w.WriteHeader(http.StatusConflict)
+			errorResponse := models.NewErrorResponse("Приглашение уже отправлено.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при создании приглашения: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при создании приглашения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionInvitationAdd, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), nil, invitation)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invitation)
+}
+
+// DeleteInvitationHandler отзывает приглашение. Уже поданные предложения остаются.
+func DeleteInvitationHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, _, ok := authorizeTender(w, r, models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
+	}
+
+	inviteeId, err := uuid.Parse(mux.Vars(r)["inviteeId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора приглашённого.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var removed []models.TenderInvitation
+	result := db.DB.Clauses(clause.Returning{}).Where("tender_id = ? AND invitee_id = ?", tender.ID, inviteeId).Delete(&removed)
+	if result.Error != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при удалении приглашения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if result.RowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := models.NewErrorResponse("Приглашение не найдено.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionInvitationRemove, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), removed[0], nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
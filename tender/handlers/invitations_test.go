@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+)
+
+func TestGetTenderStatusVisibility(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	outsider := createEmployee(t)
+	invited := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+
+	draft := createTender(t, organization, owner, models.TENDER_CREATED, nil)
+	inviteOnly := createTender(t, organization, owner, models.TENDER_PUBLISHED, func(tender *models.Tender) {
+		tender.Visibility = models.VISIBILITY_INVITE_ONLY
+	})
+	invitation := models.TenderInvitation{
+		ID:          uuid.New(),
+		TenderID:    inviteOnly.ID,
+		InviteeType: models.AUTHOR_USER,
+		InviteeID:   invited.ID,
+		InvitedByID: owner.ID,
+		CreatedAt:   time.Now(),
+	}
+	if err := db.DB.Omit("Tender").Create(&invitation).Error; err != nil {
+		t.Fatalf("create invitation: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tender models.Tender
+		as     principal
+		want   int
+	}{
+		{"черновик своей организации", draft, asEmployee(owner), http.StatusOK},
+		{"чужой черновик", draft, asEmployee(outsider), http.StatusNotFound},
+		{"тендер по приглашению без приглашения", inviteOnly, asEmployee(outsider), http.StatusNotFound},
+		{"тендер по приглашению для приглашённого", inviteOnly, asEmployee(invited), http.StatusOK},
+		{"аноним", inviteOnly, principal{}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AUTH_ALLOW_USERNAME_PARAM", "false")
+
+			w := serve(t, http.MethodGet, "/api/tenders/"+tt.tender.ID.String()+"/status", nil, tt.as)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateInvitationConcurrent(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	invitee := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, func(tender *models.Tender) {
+		tender.Visibility = models.VISIBILITY_INVITE_ONLY
+	})
+	request := models.InvitationRequest{InviteeType: models.AUTHOR_USER, InviteeID: invitee.ID.String()}
+
+	const invites = 8
+	codes := make([]int, invites)
+	var wg sync.WaitGroup
+	for i := 0; i < invites; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serve(t, http.MethodPost, "/api/tenders/"+tender.ID.String()+"/invitations", request, asEmployee(owner)).Code
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("unexpected status %d", code)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("created %d invitations, want 1", created)
+	}
+}
@@ -0,0 +1,572 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"tender/audit"
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetOrganizationsHandler(w http.ResponseWriter, r *http.Request) {
+
+	pg, err := parsePage(r, sortByName)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse(err.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	query := db.DB.Model(&models.Organization{}).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении организаций.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var organizations []models.Organization
+	if err := pg.apply(query).Find(&organizations).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении организаций.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var last pageCursor
+	if len(organizations) > 0 {
+		organization := organizations[len(organizations)-1]
+		last = pg.cursor(organization.Name, organization.ID)
+	}
+
+	writePageHeaders(w, pg, total, len(organizations), last)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(organizations)
+}
+
+func CreateOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+
+	var newOrganizationRequest models.NewOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&newOrganizationRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if newOrganizationRequest.Name == "" || len(newOrganizationRequest.Name) > 100 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поле name обязательно и не длиннее 100 символов."))
+		return
+	}
+
+	if !newOrganizationRequest.Type.IsValid() {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поле type должно быть одним из IE, LLC, JSC."))
+		return
+	}
+
+	organization := models.Organization{
+		ID:          uuid.New(),
+		Name:        newOrganizationRequest.Name,
+		Description: newOrganizationRequest.Description,
+		Type:        newOrganizationRequest.Type,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := db.DB.Create(&organization).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при создании организации: %v", err)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка при создании организации."))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(organization)
+}
+
+func GetOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+
+	organizationId, err := uuid.Parse(mux.Vars(r)["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var organization models.Organization
+	if err := db.DB.First(&organization, "id = ?", organizationId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Организация не найдена.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(organization)
+}
+
+func EditOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+
+	organizationId, err := uuid.Parse(mux.Vars(r)["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var updateData models.NewOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateData); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if len(updateData.Name) > 100 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поле name не длиннее 100 символов."))
+		return
+	}
+
+	if updateData.Type != "" && !updateData.Type.IsValid() {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поле type должно быть одним из IE, LLC, JSC."))
+		return
+	}
+
+	var organization models.Organization
+	if err := db.DB.First(&organization, "id = ?", organizationId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Организация не найдена.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if updateData.Name != "" {
+		organization.Name = updateData.Name
+	}
+	if updateData.Description != "" {
+		organization.Description = updateData.Description
+	}
+	if updateData.Type != "" {
+		organization.Type = updateData.Type
+	}
+	organization.UpdatedAt = time.Now()
+
+	if err := db.DB.Save(&organization).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при обновлении организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(organization)
+}
+
+func DeleteOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+
+	organizationId, err := uuid.Parse(mux.Vars(r)["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	result := db.DB.Delete(&models.Organization{}, "id = ?", organizationId)
+	if result.Error != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при удалении организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if result.RowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := models.NewErrorResponse("Организация не найдена.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func GetResponsiblesHandler(w http.ResponseWriter, r *http.Request) {
+
+	organizationId, err := uuid.Parse(mux.Vars(r)["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var count int64
+	if err := db.DB.Model(&models.Organization{}).Where("id = ?", organizationId).Count(&count).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if count == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := models.NewErrorResponse("Организация не найдена.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var responsibles []models.OrganizationResponsible
+	if err := db.DB.
+		Joins("User").
+		Where("organization_responsibles.organization_id = ?", organizationId).
+		Order(`"User".username ASC`).
+		Find(&responsibles).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении ответственных.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	responsibleResponses := make([]models.ResponsibleResponse, len(responsibles))
+	for i, responsible := range responsibles {
+		responsibleResponses[i] = responsibleResponse(responsible)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responsibleResponses)
+}
+
+func responsibleResponse(responsible models.OrganizationResponsible) models.ResponsibleResponse {
+	return models.ResponsibleResponse{
+		UserID:    responsible.UserID.String(),
+		Username:  responsible.User.Username,
+		FirstName: responsible.User.FirstName,
+		LastName:  responsible.User.LastName,
+		Role:      responsible.Role,
+	}
+}
+
+func AddResponsibleHandler(w http.ResponseWriter, r *http.Request) {
+
+	organizationId, err := uuid.Parse(mux.Vars(r)["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var newResponsibleRequest models.NewResponsibleRequest
+	if err := json.NewDecoder(r.Body).Decode(&newResponsibleRequest); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	userId, err := uuid.Parse(newResponsibleRequest.UserID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Новые участники по умолчанию только читают
+	if newResponsibleRequest.Role == "" {
+		newResponsibleRequest.Role = models.ROLE_VIEWER
+	}
+	if !newResponsibleRequest.Role.IsValid() {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Поле role должно быть одним из owner, editor, approver, viewer.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var organization models.Organization
+	if err := db.DB.First(&organization, "id = ?", organizationId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Организация не найдена.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var employee models.Employee
+	if err := db.DB.First(&employee, "id = ?", userId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Пользователь не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	responsible := models.OrganizationResponsible{
+		ID:             uuid.New(),
+		OrganizationID: organization.ID,
+		UserID:         employee.ID,
+		Role:           newResponsibleRequest.Role,
+	}
+
+	// Пользователь может отвечать за несколько организаций, но за каждую — один раз;
+	// повтор отсекает уникальный индекс (organization_id, user_id)
+	if err := db.DB.Omit("Organization", "User").Create(&responsible).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			w.WriteHeader(http.StatusConflict)
+			errorResponse := models.NewErrorResponse("Пользователь уже является ответственным в этой организации.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при добавлении ответственного: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при добавлении ответственного.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	responsible.User = employee
+
+	audit.Record(r.Context(), audit.ActionMemberAdd, models.ENTITY_ORGANIZATION, organization.ID.String(), organization.ID.String(), nil, responsibleResponse(responsible))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responsibleResponse(responsible))
+}
+
+func UpdateResponsibleRoleHandler(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	organizationId, err := uuid.Parse(vars["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	userId, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var updateData models.NewResponsibleRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateData); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if !updateData.Role.IsValid() {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Поле role должно быть одним из owner, editor, approver, viewer.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var responsible models.OrganizationResponsible
+	if err := db.DB.Joins("User").Where("organization_responsibles.organization_id = ? AND organization_responsibles.user_id = ?", organizationId, userId).First(&responsible).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Ответственный не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении ответственного.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if err := db.DB.Model(&models.OrganizationResponsible{}).Where("id = ?", responsible.ID).Update("role", updateData.Role).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при обновлении роли.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	before := responsibleResponse(responsible)
+	responsible.Role = updateData.Role
+
+	audit.Record(r.Context(), audit.ActionMemberRole, models.ENTITY_ORGANIZATION, organizationId.String(), organizationId.String(), before, responsibleResponse(responsible))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responsibleResponse(responsible))
+}
+
+func RemoveResponsibleHandler(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	organizationId, err := uuid.Parse(vars["organizationId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	userId, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var removed []models.OrganizationResponsible
+	result := db.DB.Clauses(clause.Returning{}).Where("organization_id = ? AND user_id = ?", organizationId, userId).Delete(&removed)
+	if result.Error != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при удалении ответственного.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if result.RowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := models.NewErrorResponse("Ответственный не найден.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionMemberRemove, models.ENTITY_ORGANIZATION, organizationId.String(), organizationId.String(), responsibleResponse(removed[0]), nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userOrganizationIDs возвращает идентификаторы всех организаций,
+// в которых пользователь является ответственным.
+func userOrganizationIDs(userId uuid.UUID) ([]string, error) {
+	var organizationIDs []uuid.UUID
+	err := db.DB.Model(&models.OrganizationResponsible{}).
+		Where("user_id = ?", userId).
+		Order("organization_id ASC").
+		Pluck("organization_id", &organizationIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(organizationIDs))
+	for i, id := range organizationIDs {
+		ids[i] = id.String()
+	}
+	return ids, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// organizationRole возвращает роль сотрудника в организации. found == false,
+// если сотрудник не является в ней ответственным.
+func organizationRole(userId uuid.UUID, organizationId string) (role models.OrganizationRole, found bool, err error) {
+	var responsible models.OrganizationResponsible
+	err = db.DB.Select("role").Where("organization_id = ? AND user_id = ?", organizationId, userId).First(&responsible).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return responsible.Role, true, nil
+}
+
+// authorizeEmployee проверяет, что у сотрудника есть право permission в организации.
+// При отказе пишет ответ с ошибкой и возвращает false.
+func authorizeEmployee(w http.ResponseWriter, employee *models.Employee, organizationId string, permission models.OrganizationPermission) bool {
+	role, found, err := organizationRole(employee.ID, organizationId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при проверке ответственных.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+	if !found || !role.Can(permission) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := models.NewErrorResponse("Недостаточно прав для выполнения действия.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+	return true
+}
+
+// authorizeOrganization проверяет право текущего аутентифицированного сотрудника в организации.
+func authorizeOrganization(w http.ResponseWriter, r *http.Request, organizationId uuid.UUID, permission models.OrganizationPermission) bool {
+	employee := auth.EmployeeFrom(r.Context())
+	if employee == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return false
+	}
+	return authorizeEmployee(w, employee, organizationId.String(), permission)
+}
+
+// authorizeUsername находит сотрудника по username и проверяет его право в организации.
+// Используется обработчиками, которые принимают username в режиме совместимости.
+func authorizeUsername(w http.ResponseWriter, username string, organizationId string, permission models.OrganizationPermission) (*models.Employee, bool) {
+	var employee models.Employee
+	if err := db.DB.Where("username = ?", username).First(&employee).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusUnauthorized)
+			errorResponse := models.NewErrorResponse("Пользователь не существует или некорректен.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return nil, false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return nil, false
+	}
+
+	if !authorizeEmployee(w, &employee, organizationId, permission) {
+		return nil, false
+	}
+	return &employee, true
+}
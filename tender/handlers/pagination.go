@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+)
+
+// pageCursor — непрозрачный курсор, указывающий на последнюю запись страницы.
+// Записи упорядочены по (sort column, id), поэтому курсор хранит ключ сортировки,
+// значение этого поля и id записи.
+type pageCursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"i"`
+}
+
+// sortOrder описывает сортировку списка: ключ из запроса, колонку и направление.
+type sortOrder struct {
+	Key    string
+	Column string
+	Desc   bool
+}
+
+func (o sortOrder) String() string {
+	if o.Desc {
+		return o.Key + ":desc"
+	}
+	return o.Key + ":asc"
+}
+
+var sortByName = sortOrder{Key: "name", Column: "name"}
+
+type page struct {
+	Limit  int
+	Offset int
+	Sort   sortOrder
+	After  *pageCursor
+}
+
+var (
+	errInvalidLimit  = errors.New("Неверный формат параметра limit.")
+	errInvalidOffset = errors.New("Неверный формат параметра offset.")
+	errInvalidCursor = errors.New("Неверный формат параметра after.")
+	errInvalidSort   = errors.New("Неверный формат параметров sort_by или order.")
+)
+
+func encodeCursor(c pageCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	if c.ID == uuid.Nil {
+		return nil, errInvalidCursor
+	}
+	return &c, nil
+}
+
+// parsePage разбирает параметры limit, offset и after для списка,
+// упорядоченного по sort. Значение limit больше maxLimit урезается до maxLimit.
+func parsePage(r *http.Request, sort sortOrder) (page, error) {
+	p := page{Limit: defaultLimit, Sort: sort}
+	query := r.URL.Query()
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return p, errInvalidLimit
+		}
+		if limit > maxLimit {
+			limit = maxLimit
+		}
+		p.Limit = limit
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return p, errInvalidOffset
+		}
+		p.Offset = offset
+	}
+
+	if afterStr := query.Get("after"); afterStr != "" {
+		after, err := decodeCursor(afterStr)
+		if err != nil || after.Sort != sort.String() {
+			return p, errInvalidCursor
+		}
+		p.After = after
+	}
+
+	return p, nil
+}
+
+// parseSort разбирает параметры sort_by и order. columns сопоставляет
+// допустимые значения sort_by с колонками таблицы.
+func parseSort(r *http.Request, columns map[string]string, def sortOrder) (sortOrder, error) {
+	sort := def
+	query := r.URL.Query()
+
+	if key := query.Get("sort_by"); key != "" {
+		column, ok := columns[key]
+		if !ok {
+			return sort, errInvalidSort
+		}
+		sort = sortOrder{Key: key, Column: column}
+	}
+
+	switch strings.ToLower(query.Get("order")) {
+	case "":
+	case "asc":
+		sort.Desc = false
+	case "desc":
+		sort.Desc = true
+	default:
+		return sort, errInvalidSort
+	}
+
+	return sort, nil
+}
+
+// apply добавляет к запросу стабильную сортировку, курсор и ограничения страницы.
+func (p page) apply(query *gorm.DB) *gorm.DB {
+	direction, cmp := "ASC", ">"
+	if p.Sort.Desc {
+		direction, cmp = "DESC", "<"
+	}
+	if p.After != nil {
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", p.Sort.Column, cmp), p.After.Value, p.After.ID)
+	}
+	return query.
+		Order(fmt.Sprintf("%s %s, id %s", p.Sort.Column, direction, direction)).
+		Offset(p.Offset).
+		Limit(p.Limit)
+}
+
+// cursor строит курсор на запись с указанными значением поля сортировки и id.
+func (p page) cursor(value string, id uuid.UUID) pageCursor {
+	return pageCursor{Sort: p.Sort.String(), Value: value, ID: id}
+}
+
+// writePageHeaders выставляет X-Total-Count и, если страница заполнена, X-Next-Cursor.
+func writePageHeaders(w http.ResponseWriter, p page, total int64, count int, last pageCursor) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	if count > 0 && count == p.Limit {
+		w.Header().Set("X-Next-Cursor", encodeCursor(last))
+	}
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tender/audit"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxQuestionLength = 1000
+	maxAnswerLength   = 2000
+)
+
+var errQuestionAnswered = errors.New("На вопрос уже дан ответ.")
+
+// loadVisibleTender загружает тендер из пути, если он виден текущему сотруднику
+// или организации API-ключа. Чужие черновики и тендеры без приглашения дают 404.
+// owner — зритель отвечает за организацию тендера.
+func loadVisibleTender(w http.ResponseWriter, r *http.Request) (tender models.Tender, employeeID *uuid.UUID, owner bool, ok bool) {
+	tenderId, err := uuid.Parse(mux.Vars(r)["tenderId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, nil, false, false
+	}
+
+	employeeID, organizationIDs, err := tenderViewer(r)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, nil, false, false
+	}
+	if employeeID == nil && len(organizationIDs) == 0 {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, nil, false, false
+	}
+
+	err = db.DB.Where("id = ?", tenderId).
+		Where(visibleTendersCondition(employeeID, organizationIDs)).
+		First(&tender).Error
+	if err == nil {
+		owner = containsString(organizationIDs, tender.OrganizationID.String())
+		if !owner && tender.Status == models.TENDER_CREATED {
+			err = gorm.ErrRecordNotFound
+		}
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Тендер не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return tender, nil, false, false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, nil, false, false
+	}
+
+	return tender, employeeID, owner, true
+}
+
+// questionResponse скрывает автора вопроса от всех, кроме организации тендера и самого автора.
+func questionResponse(question models.TenderQuestion, showAuthor bool) models.QuestionResponse {
+	response := models.QuestionResponse{
+		ID:               question.ID.String(),
+		TenderID:         question.TenderID.String(),
+		Question:         question.Question,
+		Answer:           question.Answer,
+		AnswerVisibility: question.AnswerVisibility,
+		AnsweredAt:       question.AnsweredAt,
+		TenderVersion:    question.TenderVersion,
+		CreatedAt:        question.CreatedAt,
+	}
+	if showAuthor {
+		authorID := question.AuthorID
+		response.AuthorID = &authorID
+	}
+	return response
+}
+
+// AskQuestionHandler принимает вопрос по опубликованному тендеру от любого
+// сотрудника, которому этот тендер виден.
+func AskQuestionHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, employeeID, _, ok := loadVisibleTender(w, r)
+	if !ok {
+		return
+	}
+
+	if employeeID == nil {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := models.NewErrorResponse("Вопросы задают сотрудники, а не API-ключи.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if tender.Status != models.TENDER_PUBLISHED {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Вопросы принимаются только по опубликованным тендерам.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var request models.NewQuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	request.Question = strings.TrimSpace(request.Question)
+	if request.Question == "" || utf8.RuneCountInString(request.Question) > maxQuestionLength {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поле question обязательно и не должно быть длиннее 1000 символов."))
+		return
+	}
+
+	question := models.TenderQuestion{
+		ID:        uuid.New(),
+		TenderID:  tender.ID,
+		AuthorID:  *employeeID,
+		Question:  request.Question,
+		CreatedAt: time.Now(),
+	}
+	if err := db.DB.Omit("Tender", "Author").Create(&question).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при сохранении вопроса: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при сохранении вопроса.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionQuestionAsk, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), nil, question)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(questionResponse(question, true))
+}
+
+// GetQuestionsHandler возвращает вопросы по тендеру. Организация тендера видит
+// все вопросы с авторами, остальные — публичные разъяснения и свои вопросы.
+func GetQuestionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, employeeID, owner, ok := loadVisibleTender(w, r)
+	if !ok {
+		return
+	}
+
+	query := db.DB.Where("tender_id = ?", tender.ID)
+	if !owner {
+		if employeeID != nil {
+			query = query.Where("(answer_visibility = ? OR author_id = ?)", models.ANSWER_PUBLIC, *employeeID)
+		} else {
+			query = query.Where("answer_visibility = ?", models.ANSWER_PUBLIC)
+		}
+	}
+
+	var questions []models.TenderQuestion
+	if err := query.Order("created_at ASC").Find(&questions).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении вопросов.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	responses := make([]models.QuestionResponse, len(questions))
+	for i, question := range questions {
+		responses[i] = questionResponse(question, owner || (employeeID != nil && question.AuthorID == *employeeID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responses)
+}
+
+// AnswerQuestionHandler отвечает на вопрос. Публичное разъяснение может выпустить
+// новую версию тендера (bumpVersion), чтобы участники увидели, что условия уточнены.
+func AnswerQuestionHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, employee, ok := authorizeTender(w, r, models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
+	}
+
+	questionId, err := uuid.Parse(mux.Vars(r)["questionId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора вопроса.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var request models.AnswerRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	request.Answer = strings.TrimSpace(request.Answer)
+	if request.Answer == "" || utf8.RuneCountInString(request.Answer) > maxAnswerLength {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Поле answer обязательно и не должно быть длиннее 2000 символов."))
+		return
+	}
+	if request.Visibility != models.ANSWER_PUBLIC && request.Visibility != models.ANSWER_PRIVATE {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Допустимые visibility: PUBLIC, PRIVATE."))
+		return
+	}
+	if request.BumpVersion && request.Visibility != models.ANSWER_PUBLIC {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Новую версию тендера выпускает только публичное разъяснение."))
+		return
+	}
+
+	var question models.TenderQuestion
+	if err := db.DB.First(&question, "id = ? AND tender_id = ?", questionId, tender.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Вопрос не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении вопроса.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if question.AnsweredAt != nil {
+		w.WriteHeader(http.StatusConflict)
+		errorResponse := models.NewErrorResponse(errQuestionAnswered.Error())
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var before models.TenderQuestion
+	var current, after models.Tender
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокировка вопроса не даёт ответить на него дважды
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&question, "id = ?", question.ID).Error; err != nil {
+			return err
+		}
+		if question.AnsweredAt != nil {
+			return errQuestionAnswered
+		}
+		before = question
+
+		now := time.Now()
+		question.Answer = request.Answer
+		question.AnswerVisibility = request.Visibility
+		question.AnsweredByID = &employee.ID
+		question.AnsweredAt = &now
+
+		if request.BumpVersion {
+			// Версию выпускаем от заблокированной строки, чтобы не затереть параллельную правку тендера
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", tender.ID).Error; err != nil {
+				return err
+			}
+			tenderVersion := current.Snapshot()
+			if err := tx.Omit("Tender").Create(&tenderVersion).Error; err != nil {
+				return err
+			}
+			after = current
+			after.UpdatedByID = &employee.ID
+			after.Version = current.Version + 1
+			if err := tx.Omit("Organization").Save(&after).Error; err != nil {
+				return err
+			}
+			question.TenderVersion = &after.Version
+		}
+		return tx.Omit("Tender", "Author").Save(&question).Error
+	})
+	if err != nil {
+		if errors.Is(err, errQuestionAnswered) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при сохранении ответа: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при сохранении ответа.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionQuestionAnswer, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), before, question)
+	if request.BumpVersion {
+		audit.Record(r.Context(), audit.ActionEdit, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), current, after)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(questionResponse(question, true))
+}
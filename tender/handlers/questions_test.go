@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"tender/db"
+	"tender/models"
+)
+
+func TestAskQuestion(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	supplier := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+	target := "/api/tenders/" + tender.ID.String() + "/questions"
+
+	// Лимит считается в символах: 1000 кириллических букв занимают 2000 байт
+	w := serve(t, http.MethodPost, target, models.NewQuestionRequest{Question: strings.Repeat("я", maxQuestionLength)}, asEmployee(supplier))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	w = serve(t, http.MethodPost, target, models.NewQuestionRequest{Question: strings.Repeat("я", maxQuestionLength+1)}, asEmployee(supplier))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("too long: status = %d, want 400: %s", w.Code, w.Body.String())
+	}
+
+	var events int64
+	if err := db.DB.Model(&models.AuditEvent{}).Where("entity_id = ? AND action = ?", tender.ID.String(), "QUESTION_ASK").Count(&events).Error; err != nil {
+		t.Fatalf("audit events: %v", err)
+	}
+	if events != 1 {
+		t.Errorf("QUESTION_ASK events = %d, want 1", events)
+	}
+}
+
+func TestAnswerQuestionConcurrent(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	supplier := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+
+	w := serve(t, http.MethodPost, "/api/tenders/"+tender.ID.String()+"/questions", models.NewQuestionRequest{Question: "Вопрос"}, asEmployee(supplier))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ask: status = %d: %s", w.Code, w.Body.String())
+	}
+	var question models.QuestionResponse
+	decode(t, w, &question)
+
+	const answers = 8
+	codes := make([]int, answers)
+	var wg sync.WaitGroup
+	for i := 0; i < answers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serve(t, http.MethodPut, "/api/tenders/"+tender.ID.String()+"/questions/"+question.ID+"/answer", models.AnswerRequest{
+				Answer:      "Ответ",
+				Visibility:  models.ANSWER_PUBLIC,
+				BumpVersion: true,
+			}, asEmployee(owner)).Code
+		}(i)
+	}
+	wg.Wait()
+
+	answered := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			answered++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("unexpected status %d", code)
+		}
+	}
+	if answered != 1 {
+		t.Fatalf("answered %d times, want 1", answered)
+	}
+	assertTenderHistory(t, tender.ID, 1)
+
+	var events int64
+	if err := db.DB.Model(&models.AuditEvent{}).Where("entity_id = ? AND action = ?", tender.ID.String(), "QUESTION_ANSWER").Count(&events).Error; err != nil {
+		t.Fatalf("audit events: %v", err)
+	}
+	if events != 1 {
+		t.Errorf("QUESTION_ANSWER events = %d, want 1", events)
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"tender/models"
+)
+
+func tenderResponse(tender models.Tender) models.TenderResponse {
+	return models.TenderResponse{
+		ID:                 tender.ID.String(),
+		Name:               tender.Name,
+		Description:        tender.Description,
+		Status:             tender.Status,
+		ServiceType:        tender.ServiceType,
+		CreatorID:          tender.CreatorID,
+		UpdatedByID:        tender.UpdatedByID,
+		SubmissionDeadline: tender.SubmissionDeadline,
+		Sealed:             tender.Sealed,
+		Prequalification:   tender.Prequalification,
+		Stage:              tender.Stage,
+		StageStatus:        tender.StageStatus,
+		Visibility:         tender.Visibility,
+		Budget:             tender.Budget,
+		BudgetCurrency:     tender.BudgetCurrency,
+		RequiredBy:         tender.RequiredBy,
+		Region:             tender.Region,
+		LineItems:          tender.LineItems,
+		Version:            tender.Version,
+		CreatedAt:          tender.CreatedAt,
+	}
+}
+
+func bidResponse(bid models.Bid) models.BidResponse {
+	return models.BidResponse{
+		ID:             bid.ID.String(),
+		Name:           bid.Name,
+		Status:         bid.Status,
+		AuthorType:     bid.AuthorType,
+		AuthorID:       bid.AuthorID.String(),
+		Amount:         bid.Amount,
+		Currency:       bid.Currency,
+		DeliveryDate:   bid.DeliveryDate,
+		WarrantyMonths: bid.WarrantyMonths,
+		PaymentTerms:   bid.PaymentTerms,
+		Stage:          bid.Stage,
+		LineItems:      bid.LineItems,
+		Decision:       bid.Decision,
+		UpdatedByID:    bid.UpdatedByID,
+		Version:        bid.Version,
+		CreatedAt:      bid.CreatedAt,
+	}
+}
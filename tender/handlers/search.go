@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"tender/db"
+	"tender/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// searchQuery объединяет запрос в русской и английской конфигурациях,
+// чтобы "доставка Казань" и "delivery" искались одинаково хорошо.
+const searchQuery = `(websearch_to_tsquery('russian', @q) || websearch_to_tsquery('english', @q))`
+
+const searchHeadlineOptions = `'MaxFragments=2, MaxWords=20, MinWords=5, StartSel=<b>, StopSel=</b>'`
+
+func SearchTendersHandler(w http.ResponseWriter, r *http.Request) {
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Параметр q обязателен.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	pg, err := parsePage(r, sortByName)
+	if err != nil || pg.After != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверные параметры пагинации, поиск поддерживает только limit и offset.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Посторонним доступны только опубликованные тендеры,
+	// ответственным — ещё и все тендеры своей организации.
+	// Тендеры по приглашению видны только приглашённым.
+	visibility := db.DB.Where("status = ?", models.TENDER_PUBLISHED)
+	invited := visibleTendersCondition(nil, nil)
+
+	if username := currentUsername(r); username != "" {
+		var employee models.Employee
+		if err := db.DB.Where("username = ?", username).First(&employee).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				w.WriteHeader(http.StatusUnauthorized)
+				errorResponse := models.NewErrorResponse("Пользователь не существует или некорректен.")
+				json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+
+		organizationIDs, err := userOrganizationIDs(employee.ID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := models.NewErrorResponse("Ошибка при получении организации.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		if len(organizationIDs) > 0 {
+			visibility = visibility.Or("organization_id IN ?", organizationIDs)
+		}
+		invited = visibleTendersCondition(&employee.ID, organizationIDs)
+	}
+
+	var results []models.TenderSearchResult
+	err = db.DB.Table("tenders").
+		Select(`id, name, description, status, service_type, version, created_at,
+			ts_rank(search_vector, `+searchQuery+`) AS rank,
+			ts_headline('russian', description, `+searchQuery+`, `+searchHeadlineOptions+`) AS snippet`,
+			map[string]interface{}{"q": q}).
+		Where("search_vector @@ "+searchQuery, map[string]interface{}{"q": q}).
+		Where(visibility).
+		Where(invited).
+		Order("rank DESC, id ASC").
+		Offset(pg.Offset).
+		Limit(pg.Limit).
+		Scan(&results).Error
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка поиска тендеров: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при поиске тендеров.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if results == nil {
+		results = []models.TenderSearchResult{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
+
+func SearchBidsHandler(w http.ResponseWriter, r *http.Request) {
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Параметр q обязателен.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	username := currentUsername(r)
+	if username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	pg, err := parsePage(r, sortByName)
+	if err != nil || pg.After != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверные параметры пагинации, поиск поддерживает только limit и offset.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var employee models.Employee
+	if err := db.DB.Where("username = ?", username).First(&employee).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusUnauthorized)
+			errorResponse := models.NewErrorResponse("Пользователь не существует или некорректен.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	organizationIDs, err := userOrganizationIDs(employee.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении организации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if len(organizationIDs) == 0 {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := models.NewErrorResponse("Недостаточно прав для выполнения действия.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Ищем только среди опубликованных предложений на тендеры организаций пользователя.
+	// Предложения закрытых торгов до срока подачи в поиск не попадают.
+	var results []models.BidSearchResult
+	err = db.DB.Table("bids").
+		Select(`id, name, status, tender_id, author_type, author_id, version, created_at,
+			ts_rank(search_vector, `+searchQuery+`) AS rank,
+			ts_headline('russian', description, `+searchQuery+`, `+searchHeadlineOptions+`) AS snippet`,
+			map[string]interface{}{"q": q}).
+		Where("search_vector @@ "+searchQuery, map[string]interface{}{"q": q}).
+		Where("status = ?", models.BID_PUBLISHED).
+		Where("tender_id IN (SELECT id FROM tenders WHERE organization_id IN ?)", organizationIDs).
+		Where("tender_id NOT IN (SELECT id FROM tenders WHERE "+sealedTendersCondition+")", time.Now()).
+		Order("rank DESC, id ASC").
+		Offset(pg.Offset).
+		Limit(pg.Limit).
+		Scan(&results).Error
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка поиска предложений: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при поиске предложений.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if results == nil {
+		results = []models.BidSearchResult{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
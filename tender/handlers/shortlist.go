@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"tender/audit"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetShortlistHandler возвращает авторов, допущенных к коммерческому этапу тендера.
+func GetShortlistHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, _, ok := authorizeTender(w, r, models.PERMISSION_READ)
+	if !ok {
+		return
+	}
+
+	var entries []models.ShortlistEntry
+	if err := db.DB.Where("tender_id = ?", tender.ID).Order("created_at ASC").Find(&entries).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении шорт-листа.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
+
+// AddToShortlistHandler допускает автора опубликованной квалификационной
+// заявки к коммерческому этапу. Шорт-лист меняется только на этапе предквалификации.
+func AddToShortlistHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, employee, ok := authorizeTender(w, r, models.PERMISSION_DECIDE_BIDS)
+	if !ok {
+		return
+	}
+
+	if tender.Stage != models.STAGE_PREQUALIFICATION {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Шорт-лист формируется только на этапе предквалификации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var request models.ShortlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Ошибка декодирования JSON: " + err.Error()))
+		return
+	}
+
+	if request.AuthorType != models.AUTHOR_USER && request.AuthorType != models.AUTHOR_ORGANIZATION {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Допустимые authorType: USER, ORGANIZATION."))
+		return
+	}
+
+	authorId, err := uuid.Parse(request.AuthorID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.NewErrorResponse("Неверный формат идентификатора автора."))
+		return
+	}
+
+	var qualified int64
+	if err := db.DB.Model(&models.Bid{}).
+		Where("tender_id = ? AND author_type = ? AND author_id = ? AND stage = ? AND status = ?",
+			tender.ID, request.AuthorType, authorId, models.STAGE_PREQUALIFICATION, models.BID_PUBLISHED).
+		Count(&qualified).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении заявок.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if qualified == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("У автора нет опубликованной квалификационной заявки по тендеру.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	entry := models.ShortlistEntry{
+		ID:         uuid.New(),
+		TenderID:   tender.ID,
+		AuthorType: request.AuthorType,
+		AuthorID:   authorId,
+		AddedByID:  employee.ID,
+		CreatedAt:  time.Now(),
+	}
+	if err := db.DB.Omit("Tender").Create(&entry).Error; err != nil {
+		// Повтор, в том числе одновременный, отсекает уникальный индекс по тендеру и автору
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			w.WriteHeader(http.StatusConflict)
+			errorResponse := models.NewErrorResponse("Автор уже в шорт-листе тендера.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при добавлении в шорт-лист: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при добавлении в шорт-лист.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionShortlistAdd, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), nil, entry)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entry)
+}
+
+func RemoveFromShortlistHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, _, ok := authorizeTender(w, r, models.PERMISSION_DECIDE_BIDS)
+	if !ok {
+		return
+	}
+
+	authorId, err := uuid.Parse(mux.Vars(r)["authorId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора автора.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if tender.Stage != models.STAGE_PREQUALIFICATION {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Шорт-лист формируется только на этапе предквалификации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var removed []models.ShortlistEntry
+	result := db.DB.Clauses(clause.Returning{}).Where("tender_id = ? AND author_id = ?", tender.ID, authorId).Delete(&removed)
+	if result.Error != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при удалении из шорт-листа.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if result.RowsAffected == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := models.NewErrorResponse("Автор не найден в шорт-листе тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionShortlistRemove, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), removed[0], nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdvanceTenderStageHandler переводит тендер с предквалификацией на следующий шаг:
+// приём квалификационных заявок → рассмотрение → приём коммерческих предложений.
+// На коммерческий этап нельзя перейти с пустым шорт-листом.
+func AdvanceTenderStageHandler(w http.ResponseWriter, r *http.Request) {
+
+	tender, employee, ok := authorizeTender(w, r, models.PERMISSION_EDIT_TENDERS)
+	if !ok {
+		return
+	}
+
+	if tender.Status != models.TENDER_PUBLISHED || tender.Stage != models.STAGE_PREQUALIFICATION {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Этап можно сменить только у опубликованного тендера на этапе предквалификации.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	after := tender
+	if tender.StageStatus == models.STAGE_OPEN {
+		after.StageStatus = models.STAGE_EVALUATION
+	} else {
+		var shortlisted int64
+		if err := db.DB.Model(&models.ShortlistEntry{}).Where("tender_id = ?", tender.ID).Count(&shortlisted).Error; err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := models.NewErrorResponse("Ошибка при проверке шорт-листа.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		if shortlisted == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := models.NewErrorResponse("Шорт-лист пуст: к коммерческому этапу никто не допущен.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		after.Stage = models.STAGE_COMMERCIAL
+		after.StageStatus = models.STAGE_OPEN
+	}
+	after.UpdatedByID = &employee.ID
+
+	// Этап выбран по прочитанной версии тендера; если её успели изменить,
+	// два одновременных перехода перескочили бы этап
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.Tender
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", tender.ID).Error; err != nil {
+			return err
+		}
+		if current.Version != tender.Version {
+			return errTenderChanged
+		}
+		tenderVersion := current.Snapshot()
+		if err := tx.Omit("Tender").Create(&tenderVersion).Error; err != nil {
+			return err
+		}
+		after.Version = current.Version + 1
+		return tx.Omit("Organization").Save(&after).Error
+	})
+	if err != nil {
+		if errors.Is(err, errTenderChanged) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(models.NewErrorResponse(err.Error()))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("Ошибка при смене этапа тендера: %v", err)
+		errorResponse := models.NewErrorResponse("Ошибка при смене этапа тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	audit.Record(r.Context(), audit.ActionStatusChange, models.ENTITY_TENDER, tender.ID.String(), tender.OrganizationID.String(), tender, after)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tenderResponse(after))
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"tender/models"
+)
+
+func TestAddToShortlistConcurrent(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	supplier := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, func(tender *models.Tender) {
+		tender.Stage = models.STAGE_PREQUALIFICATION
+	})
+	createBid(t, tender, supplier, models.BID_PUBLISHED, func(bid *models.Bid) {
+		bid.Stage = models.STAGE_PREQUALIFICATION
+	})
+	request := models.ShortlistRequest{AuthorType: models.AUTHOR_USER, AuthorID: supplier.ID.String()}
+
+	const adds = 8
+	codes := make([]int, adds)
+	var wg sync.WaitGroup
+	for i := 0; i < adds; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serve(t, http.MethodPost, "/api/tenders/"+tender.ID.String()+"/shortlist", request, asEmployee(owner)).Code
+		}(i)
+	}
+	wg.Wait()
+
+	added := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			added++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("unexpected status %d", code)
+		}
+	}
+	if added != 1 {
+		t.Fatalf("added %d times, want 1", added)
+	}
+}
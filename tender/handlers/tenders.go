@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+const (
+	maxLineItems     = 100
+	maxRegionLength  = 100
+	maxItemLength    = 200
+	maxUnitLength    = 20
+	maxBudgetOrPrice = 1e16
+)
+
+var (
+	errInvalidBudget         = errors.New("Поле budget должно быть больше нуля.")
+	errInvalidBudgetCurrency = errors.New("Поле budgetCurrency должно быть трёхбуквенным кодом ISO 4217, например RUB.")
+	errMissingBudgetCurrency = errors.New("Для поля budget обязательно поле budgetCurrency.")
+	errInvalidRequiredBy     = errors.New("Поле requiredBy должно быть в будущем.")
+	errInvalidRegion         = errors.New("Поле region не должно быть длиннее 100 символов.")
+	errInvalidLineItems      = errors.New("В lineItems не больше 100 позиций, у каждой обязательны item (до 200 символов), quantity больше нуля и unit (до 20 символов).")
+	errDuplicateLineItem     = errors.New("Идентификаторы позиций в lineItems не должны повторяться.")
+	errTenderClosed          = errors.New("Тендер закрыт, его больше нельзя открыть заново.")
+	errTenderChanged         = errors.New("Тендер изменился, пока обрабатывался запрос. Повторите правку.")
+)
+
+// applyTenderRequirements проверяет бюджет, срок, регион и позиции из запроса
+// и переносит в tender только переданные поля. Пустой список lineItems
+// очищает позиции, отсутствующий — оставляет как есть. Позициям без id
+// присваивается новый id; переданный id сохраняется, чтобы цены в предложениях
+// продолжали ссылаться на позицию после редактирования тендера.
+func applyTenderRequirements(tender *models.Tender, request models.NewTenderRequest) error {
+	if request.Budget != nil {
+		if *request.Budget <= 0 || *request.Budget >= maxBudgetOrPrice {
+			return errInvalidBudget
+		}
+		tender.Budget = request.Budget
+	}
+
+	if request.BudgetCurrency != "" {
+		currency := strings.ToUpper(request.BudgetCurrency)
+		if !currencyCode.MatchString(currency) {
+			return errInvalidBudgetCurrency
+		}
+		tender.BudgetCurrency = currency
+	}
+
+	if request.RequiredBy != nil {
+		if !request.RequiredBy.After(time.Now()) {
+			return errInvalidRequiredBy
+		}
+		tender.RequiredBy = request.RequiredBy
+	}
+
+	if request.Region != "" {
+		if len(request.Region) > maxRegionLength {
+			return errInvalidRegion
+		}
+		tender.Region = request.Region
+	}
+
+	if request.LineItems != nil {
+		if len(request.LineItems) > maxLineItems {
+			return errInvalidLineItems
+		}
+		items := make(models.TenderLineItems, len(request.LineItems))
+		seen := make(map[uuid.UUID]bool, len(request.LineItems))
+		for i, item := range request.LineItems {
+			if item.Item == "" || len(item.Item) > maxItemLength || item.Quantity <= 0 || item.Unit == "" || len(item.Unit) > maxUnitLength {
+				return errInvalidLineItems
+			}
+			if item.ID == uuid.Nil {
+				item.ID = uuid.New()
+			}
+			if seen[item.ID] {
+				return errDuplicateLineItem
+			}
+			seen[item.ID] = true
+			items[i] = item
+		}
+		tender.LineItems = items
+	}
+
+	if tender.Budget != nil && tender.BudgetCurrency == "" {
+		return errMissingBudgetCurrency
+	}
+	return nil
+}
+
+// exceedsBudget сообщает, что цена предложения выше бюджета тендера.
+// Цены в другой валюте не сравниваются.
+func exceedsBudget(bid models.Bid, tender models.Tender) bool {
+	return bid.Amount != nil && tender.Budget != nil &&
+		bid.Currency == tender.BudgetCurrency && *bid.Amount > *tender.Budget
+}
+
+// flagBudgets отмечает в ответах предложения, цена которых превышает бюджет
+// их тендера. Ошибка чтения тендеров только логируется: флаг справочный.
+func flagBudgets(responses []models.BidResponse, bids []models.Bid) {
+	var tenderIDs []uuid.UUID
+	for _, bid := range bids {
+		if bid.Amount != nil {
+			tenderIDs = append(tenderIDs, bid.TenderID)
+		}
+	}
+	if len(tenderIDs) == 0 {
+		return
+	}
+
+	var tenders []models.Tender
+	if err := db.DB.Select("id", "budget", "budget_currency").Where("id IN ? AND budget IS NOT NULL", tenderIDs).Find(&tenders).Error; err != nil {
+		log.Printf("Ошибка при получении бюджетов тендеров: %v", err)
+		return
+	}
+
+	budgets := make(map[uuid.UUID]models.Tender, len(tenders))
+	for _, tender := range tenders {
+		budgets[tender.ID] = tender
+	}
+	for i, bid := range bids {
+		if tender, ok := budgets[bid.TenderID]; ok {
+			responses[i].ExceedsBudget = exceedsBudget(bid, tender)
+		}
+	}
+}
+
+// bidResponseWithBudget строит ответ по одному предложению с флагом превышения бюджета.
+func bidResponseWithBudget(bid models.Bid) models.BidResponse {
+	responses := []models.BidResponse{bidResponse(bid)}
+	flagBudgets(responses, []models.Bid{bid})
+	return responses[0]
+}
+
+// authorizeTender загружает тендер из пути и проверяет право permission
+// текущего сотрудника в организации тендера.
+func authorizeTender(w http.ResponseWriter, r *http.Request, permission models.OrganizationPermission) (models.Tender, *models.Employee, bool) {
+	var tender models.Tender
+
+	tenderId, err := uuid.Parse(mux.Vars(r)["tenderId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, nil, false
+	}
+
+	username := currentUsername(r)
+	if username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, nil, false
+	}
+
+	if err := db.DB.First(&tender, "id = ?", tenderId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Тендер не найден.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return tender, nil, false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return tender, nil, false
+	}
+
+	employee, ok := authorizeUsername(w, username, tender.OrganizationID.String(), permission)
+	return tender, employee, ok
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"tender/auth"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateTenderAuthentication(t *testing.T) {
+	request := models.NewTenderRequest{
+		Name:            "Тендер",
+		Description:     "Описание",
+		ServiceType:     models.CONSTRUCTION,
+		OrganizationID:  uuid.NewString(),
+		CreatorUsername: "someone",
+	}
+	employee := models.Employee{ID: uuid.New(), Username: "author"}
+
+	tests := []struct {
+		name string
+		as   principal
+		want int
+	}{
+		{"аноним без режима совместимости", principal{}, http.StatusUnauthorized},
+		{"creatorUsername не совпадает с токеном", asEmployee(employee), http.StatusForbidden},
+		{"API-ключ без scope tenders:write", principal{organization: &auth.OrganizationPrincipal{OrganizationID: request.OrganizationID}}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AUTH_ALLOW_USERNAME_PARAM", "false")
+
+			w := serve(t, http.MethodPost, "/api/tenders/new", request, tt.as)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateTenderLegacyAuditActor(t *testing.T) {
+	requireDB(t)
+	t.Setenv("AUTH_ALLOW_USERNAME_PARAM", "true")
+
+	editor := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&editor: models.ROLE_EDITOR})
+
+	w := serve(t, http.MethodPost, "/api/tenders/new", models.NewTenderRequest{
+		Name:            "Тендер",
+		Description:     "Описание",
+		ServiceType:     models.CONSTRUCTION,
+		OrganizationID:  organization.ID.String(),
+		CreatorUsername: editor.Username,
+	}, principal{})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var tender models.Tender
+	decode(t, w, &tender)
+
+	// Автор из тела запроса не становится автором события аудита
+	var event models.AuditEvent
+	if err := db.DB.Where("entity_id = ? AND action = ?", tender.ID.String(), "CREATE").First(&event).Error; err != nil {
+		t.Fatalf("audit event: %v", err)
+	}
+	if event.ActorType != models.ACTOR_SYSTEM {
+		t.Errorf("actor = %s %s, want SYSTEM", event.ActorType, event.ActorID)
+	}
+}
+
+func TestUpdateTenderStatus(t *testing.T) {
+	w := serve(t, http.MethodPut, "/api/tenders/"+uuid.NewString()+"/status?status=ARCHIVED", nil, principal{})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown status: code = %d, want 400", w.Code)
+	}
+
+	requireDB(t)
+
+	owner := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+
+	tests := []struct {
+		name string
+		from models.TenderStatus
+		to   string
+		want int
+	}{
+		{"публикация", models.TENDER_CREATED, "published", http.StatusOK},
+		{"закрытие", models.TENDER_PUBLISHED, "CLOSED", http.StatusOK},
+		{"повторное открытие закрытого", models.TENDER_CLOSED, "PUBLISHED", http.StatusBadRequest},
+		{"закрытый в черновик", models.TENDER_CLOSED, "CREATED", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tender := createTender(t, organization, owner, tt.from, nil)
+
+			w := serve(t, http.MethodPut, "/api/tenders/"+tender.ID.String()+"/status?status="+tt.to, nil, asEmployee(owner))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestEditTenderKeepsOmittedFields(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_CREATED, nil)
+	target := "/api/tenders/" + tender.ID.String() + "/edit"
+
+	budget := 1000.0
+	w := serve(t, http.MethodPatch, target, models.NewTenderRequest{Budget: &budget, BudgetCurrency: "RUB"}, asEmployee(owner))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var edited models.TenderResponse
+	decode(t, w, &edited)
+	if edited.Name != tender.Name || edited.Description != tender.Description || edited.ServiceType != tender.ServiceType {
+		t.Errorf("omitted fields changed: %q %q %q", edited.Name, edited.Description, edited.ServiceType)
+	}
+	if edited.Version != 2 {
+		t.Errorf("version = %d, want 2", edited.Version)
+	}
+
+	w = serve(t, http.MethodPatch, target, models.NewTenderRequest{ServiceType: "REPAIR"}, asEmployee(owner))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown serviceType: status = %d, want 400: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestEditTenderConcurrent(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+	tender := createTender(t, organization, owner, models.TENDER_CREATED, nil)
+
+	const edits = 8
+	codes := make([]int, edits)
+	var wg sync.WaitGroup
+	for i := 0; i < edits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serve(t, http.MethodPatch, "/api/tenders/"+tender.ID.String()+"/edit", models.NewTenderRequest{
+				Name: fmt.Sprintf("Правка %d", i),
+			}, asEmployee(owner)).Code
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			succeeded++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("unexpected status %d", code)
+		}
+	}
+	if succeeded == 0 {
+		t.Fatal("no edit succeeded")
+	}
+	assertTenderHistory(t, tender.ID, succeeded)
+}
+
+func TestRollbackTender(t *testing.T) {
+	requireDB(t)
+
+	owner := createEmployee(t)
+	organization := createOrganization(t, map[*models.Employee]models.OrganizationRole{&owner: models.ROLE_OWNER})
+
+	t.Run("одновременные откаты", func(t *testing.T) {
+		tender := createTender(t, organization, owner, models.TENDER_CREATED, nil)
+		w := serve(t, http.MethodPatch, "/api/tenders/"+tender.ID.String()+"/edit", models.NewTenderRequest{Name: "Правка"}, asEmployee(owner))
+		if w.Code != http.StatusOK {
+			t.Fatalf("edit: status = %d: %s", w.Code, w.Body.String())
+		}
+
+		const rollbacks = 8
+		codes := make([]int, rollbacks)
+		var wg sync.WaitGroup
+		for i := 0; i < rollbacks; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				codes[i] = serve(t, http.MethodPut, "/api/tenders/"+tender.ID.String()+"/rollback/1", nil, asEmployee(owner)).Code
+			}(i)
+		}
+		wg.Wait()
+
+		for _, code := range codes {
+			if code != http.StatusOK {
+				t.Fatalf("rollback: status = %d", code)
+			}
+		}
+		assertTenderHistory(t, tender.ID, 1+rollbacks)
+	})
+
+	t.Run("закрытый тендер", func(t *testing.T) {
+		tender := createTender(t, organization, owner, models.TENDER_PUBLISHED, nil)
+		w := serve(t, http.MethodPatch, "/api/tenders/"+tender.ID.String()+"/edit", models.NewTenderRequest{Name: "Правка"}, asEmployee(owner))
+		if w.Code != http.StatusOK {
+			t.Fatalf("edit: status = %d: %s", w.Code, w.Body.String())
+		}
+		if err := db.DB.Model(&models.Tender{}).Where("id = ?", tender.ID).Update("status", models.TENDER_CLOSED).Error; err != nil {
+			t.Fatalf("close tender: %v", err)
+		}
+
+		w = serve(t, http.MethodPut, "/api/tenders/"+tender.ID.String()+"/rollback/1", nil, asEmployee(owner))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+// assertTenderHistory проверяет, что после changes правок у тендера версия
+// 1+changes, а в истории ровно версии 1..changes без повторов.
+func assertTenderHistory(t *testing.T, tenderID uuid.UUID, changes int) {
+	t.Helper()
+
+	var current models.Tender
+	if err := db.DB.First(&current, "id = ?", tenderID).Error; err != nil {
+		t.Fatalf("reload tender: %v", err)
+	}
+	if current.Version != uint(1+changes) {
+		t.Errorf("version = %d, want %d", current.Version, 1+changes)
+	}
+
+	var versions []uint
+	if err := db.DB.Model(&models.TenderVersion{}).Where("tender_id = ?", tenderID).Order("version").Pluck("version", &versions).Error; err != nil {
+		t.Fatalf("tender versions: %v", err)
+	}
+	if len(versions) != changes {
+		t.Fatalf("history has %d versions, want %d: %v", len(versions), changes, versions)
+	}
+	for i, version := range versions {
+		if version != uint(i+1) {
+			t.Fatalf("history versions = %v, want 1..%d", versions, changes)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"tender/db"
+	"tender/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+func GetTenderVersionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	tenderId, err := uuid.Parse(mux.Vars(r)["tenderId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	username := currentUsername(r)
+	if username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var versions []models.TenderVersion
+	if err := db.DB.Where("tender_id = ?", tenderId.String()).Order("version ASC, created_at ASC").Find(&versions).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении версий тендера.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Историю видят только ответственные за организацию тендера
+	organizationID := ""
+	if len(versions) > 0 {
+		organizationID = versions[0].OrganizationID.String()
+	} else {
+		var tender models.Tender
+		if err := db.DB.First(&tender, "id = ?", tenderId).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				w.WriteHeader(http.StatusNotFound)
+				errorResponse := models.NewErrorResponse("Тендер не найден.")
+				json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		organizationID = tender.OrganizationID.String()
+	}
+
+	if _, ok := authorizeUsername(w, username, organizationID, models.PERMISSION_READ); !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(versions)
+}
+
+func GetBidVersionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	bidId, err := uuid.Parse(mux.Vars(r)["bidId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := models.NewErrorResponse("Неверный формат идентификатора предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	username := currentUsername(r)
+	if username == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := models.NewErrorResponse("Пользователь не аутентифицирован.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var bid models.Bid
+	if err := db.DB.First(&bid, "id = ?", bidId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			errorResponse := models.NewErrorResponse("Предложение не найдено.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var employee models.Employee
+	if err := db.DB.Where("username = ?", username).First(&employee).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusUnauthorized)
+			errorResponse := models.NewErrorResponse("Пользователь не существует или некорректен.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении пользователя.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Историю видят автор предложения и ответственные за организацию тендера,
+	// но при закрытых торгах — только после срока подачи
+	if bid.AuthorID != employee.ID {
+		var tender models.Tender
+		if err := db.DB.First(&tender, "id = ?", bid.TenderID).Error; err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := models.NewErrorResponse("Ошибка при получении тендера.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		if !authorizeEmployee(w, &employee, tender.OrganizationID.String(), models.PERMISSION_READ) {
+			return
+		}
+		if bidsSealed(tender) {
+			w.WriteHeader(http.StatusForbidden)
+			errorResponse := models.NewErrorResponse("Предложения закрытых торгов скрыты до окончания срока подачи.")
+			json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+	}
+
+	var versions []models.BidVersion
+	if err := db.DB.Where("bid_id = ?", bidId.String()).Order("version ASC, created_at ASC").Find(&versions).Error; err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := models.NewErrorResponse("Ошибка при получении версий предложения.")
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(versions)
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +18,12 @@ type Employee struct {
 	UpdatedAt time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"updatedAt"`
 }
 
+type NewEmployeeRequest struct {
+	Username  string `json:"username"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
 type OrganizationType string
 
 const (
@@ -23,6 +32,14 @@ const (
 	JSC OrganizationType = "JSC"
 )
 
+func (t OrganizationType) IsValid() bool {
+	switch t {
+	case IE, LLC, JSC:
+		return true
+	}
+	return false
+}
+
 type Organization struct {
 	ID          uuid.UUID        `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
 	Name        string           `gorm:"not null;size:100" json:"name"`
@@ -32,12 +49,121 @@ type Organization struct {
 	UpdatedAt   time.Time        `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"updatedAt"`
 }
 
+type NewOrganizationRequest struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Type        OrganizationType `json:"type"`
+}
+
+type OrganizationRole string
+
+const (
+	ROLE_OWNER    OrganizationRole = "owner"
+	ROLE_EDITOR   OrganizationRole = "editor"
+	ROLE_APPROVER OrganizationRole = "approver"
+	ROLE_VIEWER   OrganizationRole = "viewer"
+)
+
+func (r OrganizationRole) IsValid() bool {
+	switch r {
+	case ROLE_OWNER, ROLE_EDITOR, ROLE_APPROVER, ROLE_VIEWER:
+		return true
+	}
+	return false
+}
+
+type OrganizationPermission string
+
+const (
+	PERMISSION_READ           OrganizationPermission = "read"
+	PERMISSION_EDIT_TENDERS   OrganizationPermission = "tenders:edit"
+	PERMISSION_DECIDE_BIDS    OrganizationPermission = "bids:decide"
+	PERMISSION_MANAGE_MEMBERS OrganizationPermission = "members:manage"
+)
+
+var rolePermissions = map[OrganizationRole][]OrganizationPermission{
+	ROLE_OWNER:    {PERMISSION_READ, PERMISSION_EDIT_TENDERS, PERMISSION_DECIDE_BIDS, PERMISSION_MANAGE_MEMBERS},
+	ROLE_EDITOR:   {PERMISSION_READ, PERMISSION_EDIT_TENDERS},
+	ROLE_APPROVER: {PERMISSION_READ, PERMISSION_DECIDE_BIDS},
+	ROLE_VIEWER:   {PERMISSION_READ},
+}
+
+func (r OrganizationRole) Can(permission OrganizationPermission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 type OrganizationResponsible struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID        `gorm:"not null;uniqueIndex:idx_organization_responsible_member" json:"organizationId"`
+	UserID         uuid.UUID        `gorm:"not null;uniqueIndex:idx_organization_responsible_member" json:"userId"`
+	Role           OrganizationRole `gorm:"type:organization_role;not null;default:'owner'" json:"role"`
+	Organization   Organization     `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE" json:"organization"`
+	User           Employee         `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user"`
+}
+
+type APIKeyScope string
+
+const (
+	SCOPE_TENDERS_WRITE   APIKeyScope = "tenders:write"
+	SCOPE_BIDS_READ       APIKeyScope = "bids:read"
+	SCOPE_DECISIONS_WRITE APIKeyScope = "decisions:write"
+)
+
+func (s APIKeyScope) IsValid() bool {
+	switch s {
+	case SCOPE_TENDERS_WRITE, SCOPE_BIDS_READ, SCOPE_DECISIONS_WRITE:
+		return true
+	}
+	return false
+}
+
+// OrganizationAPIKey хранит только SHA-256 от ключа, сам ключ показывается один раз при создании.
+type OrganizationAPIKey struct {
 	ID             uuid.UUID    `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
-	OrganizationID uuid.UUID    `gorm:"not null" json:"organizationId"`
-	UserID         uuid.UUID    `gorm:"not null" json:"userId"`
-	Organization   Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE" json:"organization"`
-	User           Employee     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user"`
+	OrganizationID uuid.UUID    `gorm:"type:uuid;not null;index" json:"organizationId"`
+	Organization   Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE" json:"-"`
+	Name           string       `gorm:"not null;size:100" json:"name"`
+	Prefix         string       `gorm:"not null;size:16" json:"prefix"`
+	KeyHash        string       `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	Scopes         string       `gorm:"not null" json:"-"`
+	CreatedAt      time.Time    `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	LastUsedAt     *time.Time   `gorm:"type:timestamptz" json:"lastUsedAt"`
+	RevokedAt      *time.Time   `gorm:"type:timestamptz" json:"revokedAt"`
+}
+
+type NewAPIKeyRequest struct {
+	Name   string        `json:"name"`
+	Scopes []APIKeyScope `json:"scopes"`
+}
+
+type APIKeyResponse struct {
+	ID             string        `json:"id"`
+	OrganizationID string        `json:"organizationId"`
+	Name           string        `json:"name"`
+	Prefix         string        `json:"prefix"`
+	Scopes         []APIKeyScope `json:"scopes"`
+	Key            string        `json:"key,omitempty"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	LastUsedAt     *time.Time    `json:"lastUsedAt"`
+	RevokedAt      *time.Time    `json:"revokedAt"`
+}
+
+type NewResponsibleRequest struct {
+	UserID string           `json:"userId"`
+	Role   OrganizationRole `json:"role"`
+}
+
+type ResponsibleResponse struct {
+	UserID    string           `json:"userId"`
+	Username  string           `json:"username"`
+	FirstName string           `json:"firstName"`
+	LastName  string           `json:"lastName"`
+	Role      OrganizationRole `json:"role"`
 }
 
 type TenderStatus string
@@ -48,6 +174,14 @@ const (
 	TENDER_CLOSED    TenderStatus = "CLOSED"
 )
 
+func (s TenderStatus) IsValid() bool {
+	switch s {
+	case TENDER_CREATED, TENDER_PUBLISHED, TENDER_CLOSED:
+		return true
+	}
+	return false
+}
+
 type TenderServiceType string
 
 const (
@@ -56,45 +190,195 @@ const (
 	MANUFACTURE  TenderServiceType = "MANUFACTURE"
 )
 
+func (t TenderServiceType) IsValid() bool {
+	switch t {
+	case CONSTRUCTION, DELIVERY, MANUFACTURE:
+		return true
+	}
+	return false
+}
+
+type TenderStage string
+
+const (
+	STAGE_PREQUALIFICATION TenderStage = "PREQUALIFICATION"
+	STAGE_COMMERCIAL       TenderStage = "COMMERCIAL"
+)
+
+// TenderStageStatus — приём заявок на текущем этапе открыт или завершён
+// и организация рассматривает поданные заявки.
+type TenderStageStatus string
+
+const (
+	STAGE_OPEN       TenderStageStatus = "OPEN"
+	STAGE_EVALUATION TenderStageStatus = "EVALUATION"
+)
+
+type TenderVisibility string
+
+const (
+	VISIBILITY_PUBLIC      TenderVisibility = "PUBLIC"
+	VISIBILITY_INVITE_ONLY TenderVisibility = "INVITE_ONLY"
+)
+
+func (v TenderVisibility) IsValid() bool {
+	switch v {
+	case VISIBILITY_PUBLIC, VISIBILITY_INVITE_ONLY:
+		return true
+	}
+	return false
+}
+
 type Tender struct {
-	ID             uuid.UUID         `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
-	Name           string            `gorm:"not null;size:100" json:"name"`
-	Description    string            `gorm:"not null;size:500" json:"description"`
-	Status         TenderStatus      `gorm:"type:tender_status;default:'CREATED'" json:"status"`
-	ServiceType    TenderServiceType `gorm:"type:tender_service_type" json:"serviceType"`
-	OrganizationID string            `gorm:"not null;size:100" json:"organizationId"`
-	Version        uint              `gorm:"default:1;not null" json:"version"`
-	CreatedAt      time.Time         `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	ID                 uuid.UUID         `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
+	Name               string            `gorm:"not null;size:100;index:idx_tenders_organization_name,priority:2" json:"name"`
+	Description        string            `gorm:"not null;size:500" json:"description"`
+	Status             TenderStatus      `gorm:"type:tender_status;default:'CREATED'" json:"status"`
+	ServiceType        TenderServiceType `gorm:"type:tender_service_type" json:"serviceType"`
+	OrganizationID     uuid.UUID         `gorm:"type:uuid;not null;index:idx_tenders_organization_name,priority:1" json:"organizationId"`
+	Organization       Organization      `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE" json:"-"`
+	CreatorID          *uuid.UUID        `gorm:"type:uuid" json:"creatorId"`
+	UpdatedByID        *uuid.UUID        `gorm:"type:uuid" json:"updatedById"`
+	SubmissionDeadline *time.Time        `gorm:"type:timestamptz;index" json:"submissionDeadline"`
+	Budget             *float64          `gorm:"type:numeric(18,2)" json:"budget"`
+	BudgetCurrency     string            `gorm:"size:3" json:"budgetCurrency"`
+	RequiredBy         *time.Time        `gorm:"type:timestamptz" json:"requiredBy"`
+	Region             string            `gorm:"size:100" json:"region"`
+	LineItems          TenderLineItems   `gorm:"type:jsonb" json:"lineItems"`
+	Sealed             bool              `gorm:"not null;default:false" json:"sealed"`
+	Prequalification   bool              `gorm:"not null;default:false" json:"prequalification"`
+	Stage              TenderStage       `gorm:"size:20;not null;default:'COMMERCIAL'" json:"stage"`
+	StageStatus        TenderStageStatus `gorm:"size:20;not null;default:'OPEN'" json:"stageStatus"`
+	Visibility         TenderVisibility  `gorm:"size:20;not null;default:'PUBLIC'" json:"visibility"`
+	Version            uint              `gorm:"default:1;not null" json:"version"`
+	CreatedAt          time.Time         `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+type TenderLineItem struct {
+	ID       uuid.UUID `json:"id"`
+	Item     string    `json:"item"`
+	Quantity float64   `json:"quantity"`
+	Unit     string    `json:"unit"`
+}
+
+// TenderLineItems хранится в jsonb, чтобы позиции попадали в версии тендера целиком.
+type TenderLineItems []TenderLineItem
+
+func (items TenderLineItems) Value() (driver.Value, error) {
+	if items == nil {
+		return nil, nil
+	}
+	return json.Marshal(items)
+}
+
+func (items *TenderLineItems) Scan(value interface{}) error {
+	return scanJSON(value, items)
+}
+
+// scanJSON разбирает значение jsonb-колонки в dest.
+func scanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	}
+	return errors.New("неподдерживаемый тип jsonb-колонки")
 }
 
 type NewTenderRequest struct {
-	Name            string            `json:"name"`
-	Description     string            `json:"description"`
-	ServiceType     TenderServiceType `json:"serviceType"`
-	OrganizationID  string            `json:"organizationId"`
-	CreatorUsername string            `json:"creatorUsername"`
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	ServiceType        TenderServiceType `json:"serviceType"`
+	OrganizationID     string            `json:"organizationId"`
+	CreatorUsername    string            `json:"creatorUsername"`
+	SubmissionDeadline *time.Time        `json:"submissionDeadline"`
+	Budget             *float64          `json:"budget"`
+	BudgetCurrency     string            `json:"budgetCurrency"`
+	RequiredBy         *time.Time        `json:"requiredBy"`
+	Region             string            `json:"region"`
+	LineItems          TenderLineItems   `json:"lineItems"`
+	Sealed             bool              `json:"sealed"`
+	Prequalification   bool              `json:"prequalification"`
+	Visibility         TenderVisibility  `json:"visibility"`
 }
 
 type TenderResponse struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	Status      TenderStatus      `json:"status"`
-	ServiceType TenderServiceType `json:"serviceType"`
-	Version     uint              `json:"version"`
-	CreatedAt   time.Time         `json:"createdAt"`
+	ID                 string            `json:"id"`
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	Status             TenderStatus      `json:"status"`
+	ServiceType        TenderServiceType `json:"serviceType"`
+	CreatorID          *uuid.UUID        `json:"creatorId,omitempty"`
+	UpdatedByID        *uuid.UUID        `json:"updatedById,omitempty"`
+	SubmissionDeadline *time.Time        `json:"submissionDeadline,omitempty"`
+	Budget             *float64          `json:"budget,omitempty"`
+	BudgetCurrency     string            `json:"budgetCurrency,omitempty"`
+	RequiredBy         *time.Time        `json:"requiredBy,omitempty"`
+	Region             string            `json:"region,omitempty"`
+	LineItems          TenderLineItems   `json:"lineItems,omitempty"`
+	Sealed             bool              `json:"sealed"`
+	Prequalification   bool              `json:"prequalification"`
+	Stage              TenderStage       `json:"stage"`
+	StageStatus        TenderStageStatus `json:"stageStatus"`
+	Visibility         TenderVisibility  `json:"visibility"`
+	Version            uint              `json:"version"`
+	CreatedAt          time.Time         `json:"createdAt"`
 }
 
 type TenderVersion struct {
-	ID             uuid.UUID         `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
-	TenderID       string            `gorm:"not null" json:"tenderId"`
-	Name           string            `gorm:"not null;size:100" json:"name"`
-	Description    string            `gorm:"not null;size:500" json:"description"`
-	Status         TenderStatus      `gorm:"type:tender_status;default:'CREATED'" json:"status"`
-	ServiceType    TenderServiceType `gorm:"type:tender_service_type;not null" json:"serviceType"`
-	OrganizationID string            `gorm:"not null" json:"organizationId"`
-	Version        uint              `gorm:"not null" json:"version"`
-	CreatedAt      time.Time         `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	ID                 uuid.UUID         `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
+	TenderID           uuid.UUID         `gorm:"type:uuid;not null;index:idx_tender_versions_tender_version,priority:1" json:"tenderId"`
+	Tender             Tender            `gorm:"foreignKey:TenderID;constraint:OnDelete:CASCADE" json:"-"`
+	Name               string            `gorm:"not null;size:100" json:"name"`
+	Description        string            `gorm:"not null;size:500" json:"description"`
+	Status             TenderStatus      `gorm:"type:tender_status;default:'CREATED'" json:"status"`
+	ServiceType        TenderServiceType `gorm:"type:tender_service_type;not null" json:"serviceType"`
+	OrganizationID     uuid.UUID         `gorm:"type:uuid;not null" json:"organizationId"`
+	CreatorID          *uuid.UUID        `gorm:"type:uuid" json:"creatorId"`
+	UpdatedByID        *uuid.UUID        `gorm:"type:uuid" json:"updatedById"`
+	SubmissionDeadline *time.Time        `gorm:"type:timestamptz" json:"submissionDeadline"`
+	Budget             *float64          `gorm:"type:numeric(18,2)" json:"budget"`
+	BudgetCurrency     string            `gorm:"size:3" json:"budgetCurrency"`
+	RequiredBy         *time.Time        `gorm:"type:timestamptz" json:"requiredBy"`
+	Region             string            `gorm:"size:100" json:"region"`
+	LineItems          TenderLineItems   `gorm:"type:jsonb" json:"lineItems"`
+	Sealed             bool              `gorm:"not null;default:false" json:"sealed"`
+	Prequalification   bool              `gorm:"not null;default:false" json:"prequalification"`
+	Stage              TenderStage       `gorm:"size:20;not null;default:'COMMERCIAL'" json:"stage"`
+	StageStatus        TenderStageStatus `gorm:"size:20;not null;default:'OPEN'" json:"stageStatus"`
+	Visibility         TenderVisibility  `gorm:"size:20;not null;default:'PUBLIC'" json:"visibility"`
+	Version            uint              `gorm:"not null;index:idx_tender_versions_tender_version,priority:2" json:"version"`
+	CreatedAt          time.Time         `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+// Snapshot снимает копию текущего состояния тендера для tender_versions.
+func (tender Tender) Snapshot() TenderVersion {
+	return TenderVersion{
+		TenderID:           tender.ID,
+		Name:               tender.Name,
+		Description:        tender.Description,
+		Status:             tender.Status,
+		ServiceType:        tender.ServiceType,
+		OrganizationID:     tender.OrganizationID,
+		CreatorID:          tender.CreatorID,
+		UpdatedByID:        tender.UpdatedByID,
+		SubmissionDeadline: tender.SubmissionDeadline,
+		Sealed:             tender.Sealed,
+		Prequalification:   tender.Prequalification,
+		Stage:              tender.Stage,
+		StageStatus:        tender.StageStatus,
+		Visibility:         tender.Visibility,
+		Budget:             tender.Budget,
+		BudgetCurrency:     tender.BudgetCurrency,
+		RequiredBy:         tender.RequiredBy,
+		Region:             tender.Region,
+		LineItems:          tender.LineItems,
+		Version:            tender.Version,
+		CreatedAt:          tender.CreatedAt,
+	}
 }
 
 type BidStatus string
@@ -105,6 +389,14 @@ const (
 	BID_CANCELED  BidStatus = "CANCELED"
 )
 
+func (s BidStatus) IsValid() bool {
+	switch s {
+	case BID_CREATED, BID_PUBLISHED, BID_CANCELED:
+		return true
+	}
+	return false
+}
+
 type BidAuthorType string
 
 const (
@@ -112,47 +404,493 @@ const (
 	AUTHOR_USER         BidAuthorType = "USER"
 )
 
+type BidPaymentTerms string
+
+const (
+	PAYMENT_PREPAYMENT         BidPaymentTerms = "PREPAYMENT"
+	PAYMENT_PARTIAL_PREPAYMENT BidPaymentTerms = "PARTIAL_PREPAYMENT"
+	PAYMENT_POSTPAYMENT        BidPaymentTerms = "POSTPAYMENT"
+)
+
+func (t BidPaymentTerms) IsValid() bool {
+	switch t {
+	case PAYMENT_PREPAYMENT, PAYMENT_PARTIAL_PREPAYMENT, PAYMENT_POSTPAYMENT:
+		return true
+	}
+	return false
+}
+
+// BidLineItem — цена предложения по одной позиции тендера. Total вычисляется сервером.
+type BidLineItem struct {
+	LineItemID uuid.UUID `json:"lineItemId"`
+	Quantity   float64   `json:"quantity"`
+	UnitPrice  float64   `json:"unitPrice"`
+	Total      float64   `json:"total"`
+}
+
+type BidLineItems []BidLineItem
+
+func (items BidLineItems) Value() (driver.Value, error) {
+	if items == nil {
+		return nil, nil
+	}
+	return json.Marshal(items)
+}
+
+func (items *BidLineItems) Scan(value interface{}) error {
+	return scanJSON(value, items)
+}
+
+// AuthorID предложения ссылается на сотрудника или организацию в зависимости
+// от AuthorType, поэтому внешнего ключа у него нет.
 type Bid struct {
-	ID          uuid.UUID     `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
-	Name        string        `gorm:"not null;size:100" json:"name"`
-	Description string        `gorm:"not null;size:500" json:"description"`
-	Status      BidStatus     `gorm:"type:bid_status;default:'CREATED'" json:"status"`
-	TenderID    string        `gorm:"not null;size:100" json:"tenderId"`
-	AuthorType  BidAuthorType `gorm:"type:bid_author_type; not null" json:"authorType"`
-	AuthorID    string        `gorm:"not null;size:100;default:uuid_generate_v4()" json:"authorId"`
-	Version     uint          `gorm:"default:1;not null" json:"version"`
-	CreatedAt   time.Time     `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
+	Name           string          `gorm:"not null;size:100" json:"name"`
+	Description    string          `gorm:"not null;size:500" json:"description"`
+	Status         BidStatus       `gorm:"type:bid_status;default:'CREATED'" json:"status"`
+	TenderID       uuid.UUID       `gorm:"type:uuid;not null;index:idx_bids_tender_author,priority:1" json:"tenderId"`
+	Tender         Tender          `gorm:"foreignKey:TenderID;constraint:OnDelete:CASCADE" json:"-"`
+	AuthorType     BidAuthorType   `gorm:"type:bid_author_type; not null" json:"authorType"`
+	AuthorID       uuid.UUID       `gorm:"type:uuid;not null;index:idx_bids_tender_author,priority:2;index" json:"authorId"`
+	Amount         *float64        `gorm:"type:numeric(18,2)" json:"amount"`
+	Currency       string          `gorm:"size:3" json:"currency"`
+	DeliveryDate   *time.Time      `gorm:"type:timestamptz" json:"deliveryDate"`
+	WarrantyMonths *int            `json:"warrantyMonths"`
+	PaymentTerms   BidPaymentTerms `gorm:"size:20" json:"paymentTerms"`
+	LineItems      BidLineItems    `gorm:"type:jsonb" json:"lineItems"`
+	Stage          TenderStage     `gorm:"size:20;not null;default:'COMMERCIAL'" json:"stage"`
+	Decision       Decision        `gorm:"size:20" json:"decision"`
+	UpdatedByID    *uuid.UUID      `gorm:"type:uuid" json:"updatedById"`
+	Version        uint            `gorm:"default:1;not null" json:"version"`
+	CreatedAt      time.Time       `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
 }
 
 type NewBidRequest struct {
-	Name        string        `json:"name"`
-	Description string        `json:"description"`
-	TenderID    string        `json:"tenderId"`
-	AuthorType  BidAuthorType `json:"authorType"`
-	AuthorID    string        `json:"authorId"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description"`
+	TenderID       string          `json:"tenderId"`
+	AuthorType     BidAuthorType   `json:"authorType"`
+	AuthorID       string          `json:"authorId"`
+	Amount         *float64        `json:"amount"`
+	Currency       string          `json:"currency"`
+	DeliveryDate   *time.Time      `json:"deliveryDate"`
+	WarrantyMonths *int            `json:"warrantyMonths"`
+	PaymentTerms   BidPaymentTerms `json:"paymentTerms"`
+	LineItems      BidLineItems    `json:"lineItems"`
 }
 
 type BidResponse struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Status         BidStatus       `json:"status"`
+	AuthorType     BidAuthorType   `json:"authorType"`
+	AuthorID       string          `json:"authorId"`
+	Amount         *float64        `json:"amount,omitempty"`
+	Currency       string          `json:"currency,omitempty"`
+	DeliveryDate   *time.Time      `json:"deliveryDate,omitempty"`
+	WarrantyMonths *int            `json:"warrantyMonths,omitempty"`
+	PaymentTerms   BidPaymentTerms `json:"paymentTerms,omitempty"`
+	LineItems      BidLineItems    `json:"lineItems,omitempty"`
+	Stage          TenderStage     `json:"stage"`
+	Decision       Decision        `json:"decision,omitempty"`
+	ExceedsBudget  bool            `json:"exceedsBudget,omitempty"`
+	UpdatedByID    *uuid.UUID      `json:"updatedById,omitempty"`
+	Version        uint            `json:"version"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// BidMatrixRow — строка матрицы сравнения: одно предложение и его цены
+// по позициям тендера в порядке BidMatrixResponse.LineItems. Позиция без цены — null.
+type BidMatrixRow struct {
+	BidID         string         `json:"bidId"`
+	Name          string         `json:"name"`
+	AuthorType    BidAuthorType  `json:"authorType"`
+	AuthorID      string         `json:"authorId"`
+	Currency      string         `json:"currency,omitempty"`
+	Amount        *float64       `json:"amount,omitempty"`
+	ExceedsBudget bool           `json:"exceedsBudget,omitempty"`
+	Prices        []*BidLineItem `json:"prices"`
+}
+
+type BidMatrixResponse struct {
+	TenderID  string          `json:"tenderId"`
+	LineItems TenderLineItems `json:"lineItems"`
+	Suppliers []BidMatrixRow  `json:"suppliers"`
+}
+
+type BidComparisonRow struct {
+	Rank           int             `json:"rank"`
+	BidID          string          `json:"bidId"`
+	Name           string          `json:"name"`
+	AuthorType     BidAuthorType   `json:"authorType"`
+	AuthorID       string          `json:"authorId"`
+	Amount         *float64        `json:"amount,omitempty"`
+	Currency       string          `json:"currency,omitempty"`
+	DeliveryDate   *time.Time      `json:"deliveryDate,omitempty"`
+	WarrantyMonths *int            `json:"warrantyMonths,omitempty"`
+	PaymentTerms   BidPaymentTerms `json:"paymentTerms,omitempty"`
+	ExceedsBudget  bool            `json:"exceedsBudget,omitempty"`
+	PriceScore     float64         `json:"priceScore"`
+	DeliveryScore  float64         `json:"deliveryScore"`
+	Score          float64         `json:"score"`
+}
+
+type ComparisonWeights struct {
+	Price    float64 `json:"price"`
+	Delivery float64 `json:"delivery"`
+}
+
+type BidComparisonResponse struct {
+	TenderID string             `json:"tenderId"`
+	Weights  ComparisonWeights  `json:"weights"`
+	Bids     []BidComparisonRow `json:"bids"`
+}
+
+type BidVersion struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
+	BidID          uuid.UUID       `gorm:"type:uuid;not null;index:idx_bid_versions_bid_version,priority:1" json:"bidId"`
+	Bid            Bid             `gorm:"foreignKey:BidID;constraint:OnDelete:CASCADE" json:"-"`
+	Name           string          `gorm:"not null;size:100" json:"name"`
+	Description    string          `gorm:"not null;size:500" json:"description"`
+	Status         BidStatus       `gorm:"type:bid_status;default:'CREATED'" json:"status"`
+	TenderID       uuid.UUID       `gorm:"type:uuid;not null" json:"tenderId"`
+	AuthorType     BidAuthorType   `gorm:"type:bid_author_type; not null" json:"authorType"`
+	AuthorID       uuid.UUID       `gorm:"type:uuid;not null" json:"authorId"`
+	Amount         *float64        `gorm:"type:numeric(18,2)" json:"amount"`
+	Currency       string          `gorm:"size:3" json:"currency"`
+	DeliveryDate   *time.Time      `gorm:"type:timestamptz" json:"deliveryDate"`
+	WarrantyMonths *int            `json:"warrantyMonths"`
+	PaymentTerms   BidPaymentTerms `gorm:"size:20" json:"paymentTerms"`
+	LineItems      BidLineItems    `gorm:"type:jsonb" json:"lineItems"`
+	Stage          TenderStage     `gorm:"size:20;not null;default:'COMMERCIAL'" json:"stage"`
+	UpdatedByID    *uuid.UUID      `gorm:"type:uuid" json:"updatedById"`
+	Version        uint            `gorm:"default:1;not null;index:idx_bid_versions_bid_version,priority:2" json:"version"`
+	CreatedAt      time.Time       `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+// Snapshot снимает копию текущего состояния предложения для bid_versions.
+// Решение по предложению в версию не входит: откат его не меняет.
+func (bid Bid) Snapshot() BidVersion {
+	return BidVersion{
+		BidID:          bid.ID,
+		Name:           bid.Name,
+		Description:    bid.Description,
+		Status:         bid.Status,
+		TenderID:       bid.TenderID,
+		AuthorType:     bid.AuthorType,
+		AuthorID:       bid.AuthorID,
+		Amount:         bid.Amount,
+		Currency:       bid.Currency,
+		DeliveryDate:   bid.DeliveryDate,
+		WarrantyMonths: bid.WarrantyMonths,
+		PaymentTerms:   bid.PaymentTerms,
+		Stage:          bid.Stage,
+		LineItems:      bid.LineItems,
+		UpdatedByID:    bid.UpdatedByID,
+		Version:        bid.Version,
+		CreatedAt:      bid.CreatedAt,
+	}
+}
+
+type TenderSearchResult struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Status      TenderStatus      `json:"status"`
+	ServiceType TenderServiceType `json:"serviceType"`
+	Version     uint              `json:"version"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	Rank        float64           `json:"rank"`
+	Snippet     string            `json:"snippet"`
+}
+
+type BidSearchResult struct {
 	ID         string        `json:"id"`
 	Name       string        `json:"name"`
 	Status     BidStatus     `json:"status"`
+	TenderID   string        `json:"tenderId"`
 	AuthorType BidAuthorType `json:"authorType"`
 	AuthorID   string        `json:"authorId"`
 	Version    uint          `json:"version"`
 	CreatedAt  time.Time     `json:"createdAt"`
+	Rank       float64       `json:"rank"`
+	Snippet    string        `json:"snippet"`
 }
 
-type BidVersion struct {
-	ID          uuid.UUID     `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
-	BidID       string        `gorm:"not null" json:"bidId"`
-	Name        string        `gorm:"not null;size:100" json:"name"`
-	Description string        `gorm:"not null;size:500" json:"description"`
-	Status      BidStatus     `gorm:"type:bid_status;default:'CREATED'" json:"status"`
-	TenderID    string        `gorm:"not null;size:100" json:"tenderId"`
-	AuthorType  BidAuthorType `gorm:"type:bid_author_type; not null" json:"authorType"`
-	AuthorID    string        `gorm:"not null;size:100" json:"authorId"`
-	Version     uint          `gorm:"default:1;not null" json:"version"`
-	CreatedAt   time.Time     `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+type AuditActorType string
+
+const (
+	ACTOR_EMPLOYEE AuditActorType = "EMPLOYEE"
+	ACTOR_API_KEY  AuditActorType = "API_KEY"
+	ACTOR_SYSTEM   AuditActorType = "SYSTEM"
+)
+
+type AuditEntityType string
+
+const (
+	ENTITY_TENDER       AuditEntityType = "TENDER"
+	ENTITY_BID          AuditEntityType = "BID"
+	ENTITY_ORGANIZATION AuditEntityType = "ORGANIZATION"
+)
+
+// AuditEvent — запись журнала аудита. Записи только добавляются и никогда не изменяются.
+type AuditEvent struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
+	ActorType      AuditActorType  `gorm:"not null;size:20" json:"actorType"`
+	ActorID        string          `gorm:"size:100;index" json:"actorId"`
+	ActorName      string          `gorm:"size:100" json:"actorName"`
+	Action         string          `gorm:"not null;size:50" json:"action"`
+	EntityType     AuditEntityType `gorm:"not null;size:20;index:idx_audit_events_entity" json:"entityType"`
+	EntityID       string          `gorm:"not null;size:100;index:idx_audit_events_entity" json:"entityId"`
+	OrganizationID string          `gorm:"size:100;index" json:"organizationId"`
+	Before         json.RawMessage `gorm:"type:jsonb" json:"before"`
+	After          json.RawMessage `gorm:"type:jsonb" json:"after"`
+	RequestID      string          `gorm:"size:100" json:"requestId"`
+	CreatedAt      time.Time       `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;index" json:"createdAt"`
+}
+
+// EvaluationCriterion — критерий оценки предложений тендера, например цена или опыт.
+type EvaluationCriterion struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
+	TenderID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_evaluation_criteria_tender_name" json:"tenderId"`
+	Tender    Tender    `gorm:"foreignKey:TenderID;constraint:OnDelete:CASCADE" json:"-"`
+	Name      string    `gorm:"not null;size:100;uniqueIndex:idx_evaluation_criteria_tender_name" json:"name"`
+	Weight    float64   `gorm:"not null" json:"weight"`
+	CreatedAt time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+type NewEvaluationCriterionRequest struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// BidScore — балл одного ответственного по одному критерию для предложения.
+// Повторная оценка заменяет предыдущую.
+type BidScore struct {
+	ID          uuid.UUID           `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
+	BidID       uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex:idx_bid_scores_evaluator" json:"bidId"`
+	Bid         Bid                 `gorm:"foreignKey:BidID;constraint:OnDelete:CASCADE" json:"-"`
+	CriterionID uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex:idx_bid_scores_evaluator" json:"criterionId"`
+	Criterion   EvaluationCriterion `gorm:"foreignKey:CriterionID;constraint:OnDelete:CASCADE" json:"-"`
+	EvaluatorID uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex:idx_bid_scores_evaluator" json:"evaluatorId"`
+	Evaluator   Employee            `gorm:"foreignKey:EvaluatorID;constraint:OnDelete:CASCADE" json:"-"`
+	Score       float64             `gorm:"not null" json:"score"`
+	UpdatedAt   time.Time           `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+}
+
+type BidScoreRequest struct {
+	CriterionID string  `json:"criterionId"`
+	Score       float64 `json:"score"`
+}
+
+type CriterionResult struct {
+	CriterionID  string  `json:"criterionId"`
+	Name         string  `json:"name"`
+	Weight       float64 `json:"weight"`
+	AverageScore float64 `json:"averageScore"`
+}
+
+type BidEvaluationResult struct {
+	Rank       int               `json:"rank"`
+	BidID      string            `json:"bidId"`
+	Name       string            `json:"name"`
+	AuthorType BidAuthorType     `json:"authorType"`
+	AuthorID   string            `json:"authorId"`
+	Evaluators int               `json:"evaluators"`
+	Criteria   []CriterionResult `json:"criteria"`
+	FinalScore float64           `json:"finalScore"`
+}
+
+// Decision — итог согласования предложения. Пустое значение — решение не принято.
+type Decision string
+
+const (
+	DECISION_APPROVED Decision = "Approved"
+	DECISION_REJECTED Decision = "Rejected"
+)
+
+func (d Decision) IsValid() bool {
+	switch d {
+	case DECISION_APPROVED, DECISION_REJECTED:
+		return true
+	}
+	return false
+}
+
+// BidDecision — решение одного ответственного или API-ключа организации
+// по предложению. Каждый из них голосует по предложению один раз.
+type BidDecision struct {
+	ID            uuid.UUID           `gorm:"type:uuid;primaryKey;size:100;default:uuid_generate_v4()" json:"id"`
+	BidID         uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex:idx_bid_decisions_responsible;uniqueIndex:idx_bid_decisions_api_key" json:"bidId"`
+	Bid           Bid                 `gorm:"foreignKey:BidID;constraint:OnDelete:CASCADE" json:"-"`
+	ResponsibleID *uuid.UUID          `gorm:"type:uuid;uniqueIndex:idx_bid_decisions_responsible" json:"responsibleId,omitempty"`
+	Responsible   *Employee           `gorm:"foreignKey:ResponsibleID;constraint:OnDelete:CASCADE" json:"-"`
+	APIKeyID      *uuid.UUID          `gorm:"type:uuid;uniqueIndex:idx_bid_decisions_api_key" json:"apiKeyId,omitempty"`
+	APIKey        *OrganizationAPIKey `gorm:"foreignKey:APIKeyID;constraint:OnDelete:CASCADE" json:"-"`
+	Decision      Decision            `gorm:"size:20;not null" json:"decision"`
+	CreatedAt     time.Time           `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+// ShortlistEntry — автор, допущенный по итогам предквалификации
+// к коммерческому этапу тендера.
+type ShortlistEntry struct {
+	ID         uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenderID   uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_shortlist_entries_tender_author,priority:1" json:"tenderId"`
+	Tender     Tender        `gorm:"foreignKey:TenderID;constraint:OnDelete:CASCADE" json:"-"`
+	AuthorType BidAuthorType `gorm:"type:bid_author_type;not null" json:"authorType"`
+	AuthorID   uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_shortlist_entries_tender_author,priority:2" json:"authorId"`
+	AddedByID  uuid.UUID     `gorm:"type:uuid;not null" json:"addedById"`
+	CreatedAt  time.Time     `gorm:"type:timestamptz;not null" json:"createdAt"`
+}
+
+type ShortlistRequest struct {
+	AuthorType BidAuthorType `json:"authorType"`
+	AuthorID   string        `json:"authorId"`
+}
+
+// TenderInvitation допускает организацию или сотрудника к тендеру с
+// видимостью INVITE_ONLY. InviteeType принимает те же значения, что и автор предложения.
+type TenderInvitation struct {
+	ID          uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenderID    uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_tender_invitations_tender_invitee,priority:1" json:"tenderId"`
+	Tender      Tender        `gorm:"foreignKey:TenderID;constraint:OnDelete:CASCADE" json:"-"`
+	InviteeType BidAuthorType `gorm:"type:bid_author_type;not null" json:"inviteeType"`
+	InviteeID   uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_tender_invitations_tender_invitee,priority:2;index" json:"inviteeId"`
+	InvitedByID uuid.UUID     `gorm:"type:uuid;not null" json:"invitedById"`
+	CreatedAt   time.Time     `gorm:"type:timestamptz;not null" json:"createdAt"`
+}
+
+type InvitationRequest struct {
+	InviteeType BidAuthorType `json:"inviteeType"`
+	InviteeID   string        `json:"inviteeId"`
+}
+
+type AnswerVisibility string
+
+const (
+	ANSWER_PUBLIC  AnswerVisibility = "PUBLIC"
+	ANSWER_PRIVATE AnswerVisibility = "PRIVATE"
+)
+
+// TenderQuestion — вопрос поставщика по тендеру и ответ организации.
+// Публичный ответ видят все, кому виден тендер, без указания автора вопроса;
+// приватный — только автор вопроса. TenderVersion — версия тендера, выпущенная
+// вместе с разъяснением, если ответ менял условия.
+type TenderQuestion struct {
+	ID               uuid.UUID        `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenderID         uuid.UUID        `gorm:"type:uuid;not null;index" json:"tenderId"`
+	Tender           Tender           `gorm:"foreignKey:TenderID;constraint:OnDelete:CASCADE" json:"-"`
+	AuthorID         uuid.UUID        `gorm:"type:uuid;not null;index" json:"authorId"`
+	Author           Employee         `gorm:"foreignKey:AuthorID;constraint:OnDelete:CASCADE" json:"-"`
+	Question         string           `gorm:"size:1000;not null" json:"question"`
+	Answer           string           `gorm:"size:2000" json:"answer"`
+	AnswerVisibility AnswerVisibility `gorm:"size:20" json:"answerVisibility"`
+	AnsweredByID     *uuid.UUID       `gorm:"type:uuid" json:"answeredById"`
+	AnsweredAt       *time.Time       `gorm:"type:timestamptz" json:"answeredAt"`
+	TenderVersion    *uint            `json:"tenderVersion"`
+	CreatedAt        time.Time        `gorm:"type:timestamptz;not null" json:"createdAt"`
+}
+
+type NewQuestionRequest struct {
+	Question string `json:"question"`
+}
+
+type AnswerRequest struct {
+	Answer      string           `json:"answer"`
+	Visibility  AnswerVisibility `json:"visibility"`
+	BumpVersion bool             `json:"bumpVersion"`
+}
+
+type QuestionResponse struct {
+	ID               string           `json:"id"`
+	TenderID         string           `json:"tenderId"`
+	AuthorID         *uuid.UUID       `json:"authorId,omitempty"`
+	Question         string           `json:"question"`
+	Answer           string           `json:"answer,omitempty"`
+	AnswerVisibility AnswerVisibility `json:"answerVisibility,omitempty"`
+	AnsweredAt       *time.Time       `json:"answeredAt,omitempty"`
+	TenderVersion    *uint            `json:"tenderVersion,omitempty"`
+	CreatedAt        time.Time        `json:"createdAt"`
+}
+
+// Attachment — метаданные вложения тендера или предложения. Содержимое лежит
+// в хранилище под ключом ID; заполнен ровно один из TenderID и BidID.
+type Attachment struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenderID     *uuid.UUID `gorm:"type:uuid;index" json:"tenderId,omitempty"`
+	Tender       Tender     `gorm:"foreignKey:TenderID;constraint:OnDelete:CASCADE" json:"-"`
+	BidID        *uuid.UUID `gorm:"type:uuid;index" json:"bidId,omitempty"`
+	Bid          Bid        `gorm:"foreignKey:BidID;constraint:OnDelete:CASCADE" json:"-"`
+	FileName     string     `gorm:"size:255;not null" json:"fileName"`
+	ContentType  string     `gorm:"size:100;not null" json:"contentType"`
+	Size         int64      `gorm:"not null" json:"size"`
+	UploadedByID uuid.UUID  `gorm:"type:uuid;not null" json:"uploadedById"`
+	CreatedAt    time.Time  `gorm:"type:timestamptz;not null" json:"createdAt"`
+}
+
+type AuctionStatus string
+
+const (
+	AUCTION_ACTIVE   AuctionStatus = "ACTIVE"
+	AUCTION_FINISHED AuctionStatus = "FINISHED"
+)
+
+// Auction — редукцион по тендеру: участники с опубликованными предложениями
+// снижают цену раундами. BestAmount — текущая лучшая цена в валюте Currency.
+type Auction struct {
+	ID                     uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TenderID               uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex" json:"tenderId"`
+	Tender                 Tender        `gorm:"foreignKey:TenderID;constraint:OnDelete:CASCADE" json:"-"`
+	Status                 AuctionStatus `gorm:"size:20;not null" json:"status"`
+	Currency               string        `gorm:"size:3;not null" json:"currency"`
+	MinStep                float64       `gorm:"type:numeric(18,2);not null" json:"minStep"`
+	BestAmount             float64       `gorm:"type:numeric(18,2);not null" json:"bestAmount"`
+	Round                  int           `gorm:"not null" json:"round"`
+	Rounds                 int           `gorm:"not null" json:"rounds"`
+	RoundDurationSeconds   int           `gorm:"not null" json:"roundDurationSeconds"`
+	ExtensionWindowSeconds int           `gorm:"not null" json:"extensionWindowSeconds"`
+	RoundEndsAt            time.Time     `gorm:"type:timestamptz;not null" json:"roundEndsAt"`
+	StartedAt              time.Time     `gorm:"type:timestamptz;not null" json:"startedAt"`
+	FinishedAt             *time.Time    `gorm:"type:timestamptz" json:"finishedAt,omitempty"`
+}
+
+// AuctionBid — шаг снижения цены предложения в раунде аукциона.
+type AuctionBid struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	AuctionID uuid.UUID `gorm:"type:uuid;not null;index:idx_auction_bids_auction_round,priority:1" json:"auctionId"`
+	Auction   Auction   `gorm:"foreignKey:AuctionID;constraint:OnDelete:CASCADE" json:"-"`
+	Round     int       `gorm:"not null;index:idx_auction_bids_auction_round,priority:2" json:"round"`
+	BidID     uuid.UUID `gorm:"type:uuid;not null;index" json:"bidId"`
+	Bid       Bid       `gorm:"foreignKey:BidID;constraint:OnDelete:CASCADE" json:"-"`
+	Amount    float64   `gorm:"type:numeric(18,2);not null" json:"amount"`
+	CreatedAt time.Time `gorm:"type:timestamptz;not null" json:"createdAt"`
+}
+
+type StartAuctionRequest struct {
+	Currency               string  `json:"currency"`
+	MinStep                float64 `json:"minStep"`
+	Rounds                 int     `json:"rounds"`
+	RoundDurationSeconds   int     `json:"roundDurationSeconds"`
+	ExtensionWindowSeconds int     `json:"extensionWindowSeconds"`
+}
+
+type AuctionBidRequest struct {
+	BidID  string  `json:"bidId"`
+	Amount float64 `json:"amount"`
+}
+
+type AuctionResponse struct {
+	Auction
+	History []AuctionBid `json:"history,omitempty"`
+}
+
+type TokenRequest struct {
+	Username string `json:"username"`
+}
+
+type TokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 type ErrorResponse struct {
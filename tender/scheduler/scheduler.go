@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"tender/audit"
+	"tender/db"
+	"tender/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultTick = time.Minute
+
+// closeExpiredLockKey — ключ advisory-блокировки Postgres. Пока одна реплика
+// закрывает просроченные тендеры, остальные пропускают свой тик.
+const closeExpiredLockKey = 7_301_039
+
+// Tick возвращает период проверки из SCHEDULER_TICK (например, 30s), по умолчанию минута.
+func Tick() time.Duration {
+	if tick, err := time.ParseDuration(os.Getenv("SCHEDULER_TICK")); err == nil && tick > 0 {
+		return tick
+	}
+	return defaultTick
+}
+
+// Start запускает фоновую горутину, которая каждые tick закрывает тендеры
+// с истёкшим сроком подачи предложений. Останавливается при отмене ctx.
+func Start(ctx context.Context, tick time.Duration) {
+	go func() {
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := CloseExpiredTenders(ctx); err != nil {
+					log.Printf("Ошибка при закрытии просроченных тендеров: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+type closedTender struct {
+	before models.Tender
+	after  models.Tender
+}
+
+// CloseExpiredTenders закрывает все незакрытые тендеры, у которых истёк срок подачи.
+// Перед закрытием текущее состояние сохраняется в tender_versions, версия тендера
+// увеличивается, а смена статуса записывается в журнал аудита от имени системы.
+func CloseExpiredTenders(ctx context.Context) error {
+	var closed []closedTender
+
+	err := db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Блокировка снимается вместе с завершением транзакции
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", closeExpiredLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		var tenders []models.Tender
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status <> ? AND submission_deadline <= ?", models.TENDER_CLOSED, time.Now()).
+			Find(&tenders).Error
+		if err != nil {
+			return err
+		}
+
+		for _, tender := range tenders {
+			tenderVersion := tender.Snapshot()
+			if err := tx.Omit("Tender").Create(&tenderVersion).Error; err != nil {
+				return err
+			}
+
+			after := tender
+			after.Status = models.TENDER_CLOSED
+			after.UpdatedByID = nil
+			after.Version = tender.Version + 1
+			if err := tx.Omit("Organization").Save(&after).Error; err != nil {
+				return err
+			}
+
+			closed = append(closed, closedTender{before: tender, after: after})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range closed {
+		log.Printf("Тендер %s закрыт по истечении срока подачи предложений", c.after.ID)
+		audit.Record(ctx, audit.ActionStatusChange, models.ENTITY_TENDER, c.after.ID.String(), c.after.OrganizationID.String(), c.before, c.after)
+	}
+	return nil
+}
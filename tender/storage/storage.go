@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BlobStore хранит содержимое вложений по ключу. Метаданные лежат в Postgres,
+// поэтому хранилищу достаточно положить, отдать и удалить байты.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var Blobs BlobStore
+
+var ErrNotFound = errors.New("объект не найден в хранилище")
+
+var errInvalidKey = errors.New("недопустимый ключ объекта")
+
+const defaultDir = "attachments"
+
+// Open подключает локальное хранилище в каталоге ATTACHMENTS_DIR (по умолчанию ./attachments).
+func Open() {
+	dir := os.Getenv("ATTACHMENTS_DIR")
+	if dir == "" {
+		dir = defaultDir
+	}
+
+	store, err := NewLocalStore(dir)
+	if err != nil {
+		log.Fatalf("Failed to open attachments storage: %v", err)
+	}
+	Blobs = store
+}
+
+// LocalStore хранит объекты файлами в одном каталоге.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// path не даёт ключу выйти за пределы каталога хранилища.
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return "", errInvalidKey
+	}
+	return filepath.Join(s.root, key), nil
+}
+
+// Put сначала пишет во временный файл, чтобы при обрыве загрузки
+// под ключом не остался недописанный объект.
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}